@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -23,6 +24,7 @@ const (
 	userAgent            = "mobilepay-go/" + LibraryVersion
 	mediaType            = "application/json"
 	ibmClientIdHeaderKey = "x-ibm-client-id"
+	correlationIdHeader  = "CorrelationId"
 	DefaultTimeout       = 10 * time.Second
 )
 
@@ -30,6 +32,9 @@ type Client struct {
 	// HTTP client used to communicate with the MobilePay App Payment API.
 	client *http.Client
 
+	// Optional timeout applied to a copy of client once all options have been applied.
+	timeout time.Duration
+
 	// Base URL for API requests.
 	BaseURL *url.URL
 
@@ -42,11 +47,36 @@ type Client struct {
 	// Optional extra HTTP headers to set on every request to the API.
 	headers map[string]string
 
+	// Masks secrets, such as the api key, in logs and error messages.
+	redactor *redactor
+
+	// Dumps requests and responses at debug level when wire logging is enabled.
+	wireLogger *wireLogger
+
+	// Optional tracer starting a span around every API call.
+	tracer Tracer
+
+	// Optional metrics recorder observing every API call.
+	metrics Metrics
+
+	// Middleware wrapping every request, outermost first.
+	middleware []Middleware
+
+	// Optional provider of the credentials, consulted on every request.
+	credentialsProvider CredentialsProvider
+
 	Logger LeveledLoggerInterface
 
+	// Optional policy used to retry failed requests. Requests are not retried if nil.
+	retryPolicy *RetryPolicy
+
+	// Optional provider of idempotency keys for payments and refunds created without one.
+	idempotencyKeyProvider IdempotencyKeyProvider
+
 	// MobilePay API services used for communicating with the API.
-	Payment *PaymentServiceOp // we are using a struct over an interface to support multiple interfaces implemented by the struct properties.
-	Webhook WebhookService
+	Payment      *PaymentServiceOp // we are using a struct over an interface to support multiple interfaces implemented by the struct properties.
+	Webhook      *WebhookServiceOp
+	PaymentPoint *PaymentPointServiceOp
 }
 
 func newDefaultHTTPClient() *http.Client {
@@ -58,7 +88,7 @@ func newDefaultHTTPClient() *http.Client {
 // RequestCompletionCallback defines the type of the request callback function
 type RequestCompletionCallback func(*http.Request, *http.Response)
 
-// ClientOpt are options for New.
+// ClientOpt are options for NewWithOptions.
 type ClientOpt func(*Client) error
 
 type Response struct {
@@ -70,6 +100,9 @@ type ErrorResponse struct {
 	// HTTP response that caused this error
 	Response *http.Response `json:"-"`
 
+	// Masks secrets in the error message.
+	redactor *redactor
+
 	// Error message
 	Message    string         `json:"message,omitempty"`
 	Conflict   *ConflictError `json:"conflict"`
@@ -93,12 +126,18 @@ func newJSONParser(resource interface{}) responseParser {
 
 // URL is the base url to the Mobilepay API.
 // You can use the constants defined in this package: DefaultBaseURL or TestBaseUrl
+// RetryPolicy enables automatic retries of failed requests, e.g. DefaultRetryPolicy().
 type Config struct {
-	HTTPClient *http.Client
-	Logger     LeveledLoggerInterface
-	URL        string
+	HTTPClient  *http.Client
+	Logger      LeveledLoggerInterface
+	URL         string
+	RetryPolicy *RetryPolicy
 }
 
+// New returns a new MobilePay API client using the given config.
+// Invalid config values are logged and the client falls back to a default value,
+// except for an invalid URL which makes every request fail. Use NewWithOptions
+// to have invalid values reported as errors.
 func New(IbmClientId, apiKey string, config *Config) *Client {
 	if config.HTTPClient == nil {
 		config.HTTPClient = newDefaultHTTPClient()
@@ -112,13 +151,56 @@ func New(IbmClientId, apiKey string, config *Config) *Client {
 		config.URL = DefaultBaseURL
 	}
 
-	baseURL, _ := url.Parse(config.URL)
+	c := newClient(IbmClientId, apiKey)
+
+	opts := []ClientOpt{
+		WithLogger(config.Logger),
+		WithHTTPClient(config.HTTPClient),
+		WithRetryPolicy(config.RetryPolicy),
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			c.Logger.Errorf("invalid client config: %v", c.redactor.String(err.Error()))
+		}
+	}
+
+	if err := WithBaseURL(config.URL)(c); err != nil {
+		c.Logger.Errorf("invalid client config: %v", c.redactor.String(err.Error()))
+		c.BaseURL = nil
+	}
+
+	return c
+}
+
+// NewWithOptions returns a new MobilePay API client configured by the given options.
+// Options are applied in order and the first invalid option is returned as an error.
+func NewWithOptions(IbmClientId, apiKey string, opts ...ClientOpt) (*Client, error) {
+	c := newClient(IbmClientId, apiKey)
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.timeout > 0 {
+		httpClient := *c.client
+		httpClient.Timeout = c.timeout
+		c.client = &httpClient
+	}
+
+	return c, nil
+}
+
+// newClient returns a client with default values for every setting.
+func newClient(IbmClientId, apiKey string) *Client {
+	baseURL, _ := url.Parse(DefaultBaseURL + "/")
 
 	c := &Client{
-		client:    config.HTTPClient,
+		client:    newDefaultHTTPClient(),
 		BaseURL:   baseURL,
 		UserAgent: userAgent,
-		Logger:    config.Logger,
+		Logger:    DefaultLeveledLogger,
 	}
 
 	// we wrap the refund service inside the payment service to follow a more RESTful approach
@@ -126,18 +208,30 @@ func New(IbmClientId, apiKey string, config *Config) *Client {
 
 	c.Payment = &PaymentServiceOp{client: c, Refund: refundService}
 	c.Webhook = &WebhookServiceOp{client: c}
+	c.PaymentPoint = &PaymentPointServiceOp{client: c}
 
 	c.headers = make(map[string]string)
 
 	c.headers[ibmClientIdHeaderKey] = IbmClientId
 	c.headers["Authorization"] = fmt.Sprintf("Bearer %s", apiKey)
 
+	c.redactor = newRedactor()
+	c.redactor.addSecrets(IbmClientId, apiKey)
+
 	return c
 }
 
 func (c *Client) NewRequest(ctx context.Context, method, urlStr string, body interface{}) (*http.Request, error) {
 	var req *http.Request
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.BaseURL == nil {
+		return nil, ErrInvalidBaseURL
+	}
+
 	u, err := c.BaseURL.Parse(urlStr)
 	if err != nil {
 		return nil, err
@@ -145,7 +239,7 @@ func (c *Client) NewRequest(ctx context.Context, method, urlStr string, body int
 
 	switch method {
 	case http.MethodGet, http.MethodHead, http.MethodOptions:
-		req, err = http.NewRequest(method, u.String(), nil)
+		req, err = http.NewRequestWithContext(ctx, method, u.String(), nil)
 		if err != nil {
 			return nil, err
 		}
@@ -159,14 +253,16 @@ func (c *Client) NewRequest(ctx context.Context, method, urlStr string, body int
 			}
 		}
 
-		req, err = http.NewRequest(method, u.String(), buf)
+		req, err = http.NewRequestWithContext(ctx, method, u.String(), buf)
 		if err != nil {
 			return nil, err
 		}
 		req.Header.Set("Content-Type", mediaType)
-	}
 
-	c.Logger.Infof("Requesting %v %v%v", req.Method, req.URL.Host, req.URL.Path)
+		if b, ok := body.(idempotentBody); ok {
+			req = markIdempotent(req, b.idempotencyKey())
+		}
+	}
 
 	for k, v := range c.headers {
 		req.Header.Add(k, v)
@@ -175,6 +271,10 @@ func (c *Client) NewRequest(ctx context.Context, method, urlStr string, body int
 	req.Header.Set("Accept", mediaType)
 	req.Header.Set("User-Agent", c.UserAgent)
 
+	if options := requestOptionsFromContext(ctx); options != nil {
+		options.apply(req)
+	}
+
 	return req, nil
 }
 
@@ -219,13 +319,53 @@ func newResponse(r *http.Response) *Response {
 }
 
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
-	resp, err := DoRequestWithClient(ctx, c.client, req)
-	if err != nil {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	if c.onRequestCompleted != nil {
-		c.onRequestCompleted(req, resp)
+	if options := requestOptionsFromContext(ctx); options != nil && options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+
+	if c.tracer == nil && c.metrics == nil {
+		return c.do(ctx, req, v)
+	}
+
+	op, _ := OperationFromContext(ctx)
+
+	var span Span
+	if c.tracer != nil {
+		ctx, span = c.tracer.Start(ctx, op, req)
+	}
+
+	start := time.Now()
+	response, err := c.do(ctx, req, v)
+	duration := time.Since(start)
+
+	var resp *http.Response
+	if response != nil {
+		resp = response.Response
+	}
+
+	if span != nil {
+		span.End(resp, err)
+	}
+
+	if c.metrics != nil {
+		c.metrics.ObserveRequest(ctx, newRequestMetric(op, req, resp, err, duration))
+	}
+
+	return response, err
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
+	op, _ := OperationFromContext(ctx)
+
+	resp, err := c.doer().Do(ctx, op, req)
+	if resp == nil {
+		return nil, err
 	}
 
 	defer func() {
@@ -250,7 +390,6 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 
 	response := newResponse(resp)
 
-	err = CheckResponse(resp)
 	if err != nil {
 		return response, err
 	}
@@ -272,9 +411,133 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	return response, err
 }
 
+// send sends a single attempt of req and logs its outcome.
+func (c *Client) send(ctx context.Context, req *http.Request, attempt int) (*http.Response, error) {
+	c.dumpRequest(req, attempt)
+
+	start := time.Now()
+	resp, err := DoRequestWithClient(ctx, c.client, req)
+	c.logRequest(ctx, req, resp, err, attempt, time.Since(start))
+
+	c.dumpResponse(resp)
+
+	return resp, err
+}
+
+// logRequest logs the outcome of a request to the MobilePay API with structured fields.
+func (c *Client) logRequest(ctx context.Context, req *http.Request, resp *http.Response, err error, attempt int, latency time.Duration) {
+	fields := []interface{}{
+		"method", req.Method,
+		"path", req.URL.Path,
+	}
+
+	level := LevelInfo
+	msg := "MobilePay request completed"
+
+	if err != nil {
+		level = LevelError
+		msg = "MobilePay request failed"
+	} else {
+		fields = append(fields, "status", resp.StatusCode)
+		if resp.StatusCode >= 400 {
+			level = LevelWarn
+		}
+	}
+
+	fields = append(fields, "latency", latency, "attempt", attempt)
+
+	if resp != nil {
+		if correlationID := responseCorrelationID(resp); correlationID != "" {
+			fields = append(fields, "correlation_id", correlationID)
+		}
+	}
+
+	if paymentId := paymentIDFromRequest(req); paymentId != "" {
+		fields = append(fields, "payment_id", paymentId)
+	}
+
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+
+	c.log(ctx, level, msg, fields...)
+}
+
+// log logs msg with the given fields after masking any secrets.
+func (c *Client) log(ctx context.Context, level Level, msg string, keysAndValues ...interface{}) {
+	if options := requestOptionsFromContext(ctx); options != nil && options.requestID != "" {
+		keysAndValues = append(keysAndValues, "request_id", options.requestID)
+	}
+	keysAndValues = append(keysAndValues, logFieldsFromContext(ctx)...)
+
+	logFields(ctx, c.Logger, level, c.redactor.String(msg), c.redactor.redactFields(keysAndValues)...)
+}
+
+// maxCorrelationIDBodyBytes is the number of bytes of an error response searched for its
+// correlation id.
+const maxCorrelationIDBodyBytes = 64 << 10
+
+// responseCorrelationID returns the correlation id of resp. At most maxCorrelationIDBodyBytes
+// of the body of an error response are read to find it and then restored, so the body can
+// still be decoded by CheckResponse.
+func responseCorrelationID(resp *http.Response) string {
+	if id := resp.Header.Get(correlationIdHeader); id != "" {
+		return id
+	}
+
+	if resp.StatusCode < 400 || resp.Body == nil {
+		return ""
+	}
+
+	data, err := peekBody(resp, maxCorrelationIDBodyBytes)
+	if err != nil {
+		return ""
+	}
+
+	conflictError := ConflictError{}
+	if json.Unmarshal(data, &conflictError) != nil {
+		return ""
+	}
+
+	return conflictError.CorrelationID
+}
+
+// paymentIDFromRequest returns the id of the payment req refers to, if any.
+func paymentIDFromRequest(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "payments" && segments[i+1] != "" {
+			return segments[i+1]
+		}
+	}
+
+	return req.URL.Query().Get("paymentId")
+}
+
 func (r *ErrorResponse) Error() string {
-	return fmt.Sprintf("%v %v: %d %v",
-		r.Response.Request.Method, r.Response.Request.URL, r.Response.StatusCode, r.Message)
+	redactor := r.redactor
+	if redactor == nil {
+		redactor = newRedactor()
+	}
+
+	message := string(redactor.JSON([]byte(r.Message)))
+	if message == "" && r.Conflict != nil {
+		message = redactor.String(r.Conflict.Error())
+	}
+
+	var s string
+	if r.Response != nil && r.Response.Request != nil {
+		s = fmt.Sprintf("%v %v: %d %v",
+			r.Response.Request.Method, redactor.URL(r.Response.Request.URL), r.StatusCode, message)
+	} else {
+		s = fmt.Sprintf("%d %v", r.StatusCode, message)
+	}
+
+	if correlationID := r.CorrelationID(); correlationID != "" {
+		s += fmt.Sprintf(" (correlation id: %s)", correlationID)
+	}
+
+	return s
 }
 
 func CheckResponse(r *http.Response) error {
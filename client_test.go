@@ -123,7 +123,7 @@ func TestClient_ErrorResponse(t *testing.T) {
 		StatusCode: http.StatusConflict,
 	}
 
-	expected := fmt.Sprintf("%s %s: %d %v", "POST", "https://bla.com", 409, "Unknown error")
+	expected := fmt.Sprintf("%s %s: %d %v (correlation id: %s)", "POST", "https://bla.com", 409, "Unknown error", "d503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a")
 
 	assert.Equal(t, expected, error.Error())
 }
@@ -0,0 +1,201 @@
+package mobilepay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return nil, errors.New("unexpected request")
+}
+
+func TestCancelledContext_Aborts_Every_Service_Method(t *testing.T) {
+	transport := &countingTransport{}
+	client, err := NewWithOptions("test", "test",
+		WithBaseURL(TestBaseUrl),
+		WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	paymentId := "186d2b31-ff25-4414-9fd1-bfe9807fa8b7"
+	webhookId := "e4a2e195-74f6-42e1-a172-83291c9d2a41"
+
+	calls := map[string]func() error{
+		"Payment.Get": func() error {
+			_, err := client.Payment.Get(ctx, ListOptions{})
+			return err
+		},
+		"Payment.All": func() error {
+			it := client.Payment.All(ctx, ListOptions{})
+			it.Next()
+			return it.Err()
+		},
+		"Payment.Find": func() error {
+			_, err := client.Payment.Find(ctx, paymentId)
+			return err
+		},
+		"Payment.Create": func() error {
+			_, err := client.Payment.Create(ctx, &PaymentParams{
+				Amount:         1050,
+				IdempotencyKey: "7347ba06-95c5-4181-82e5-7c7a23609a0e",
+				PaymentPointId: "1f8ed17f-f310-4f40-a7a4-df78185efbdd",
+				RedirectUri:    "app://callback",
+				Reference:      "test",
+				Description:    "this is a test payment",
+			})
+			return err
+		},
+		"Payment.Cancel": func() error {
+			return client.Payment.Cancel(ctx, paymentId)
+		},
+		"Payment.Capture": func() error {
+			return client.Payment.Capture(ctx, paymentId, 100)
+		},
+		"Refund.Create": func() error {
+			_, err := client.Payment.Refund.Create(ctx, &RefundParams{
+				Amount:         100,
+				IdempotencyKey: "7576910d-9789-4fef-a72e-877d89afec94",
+				PaymentId:      paymentId,
+			})
+			return err
+		},
+		"Refund.List": func() error {
+			_, err := client.Payment.Refund.List(ctx, &RefundsListOptions{})
+			return err
+		},
+		"Refund.All": func() error {
+			it := client.Payment.Refund.All(ctx, &RefundsListOptions{})
+			it.Next()
+			return it.Err()
+		},
+		"Webhook.Get": func() error {
+			_, err := client.Webhook.Get(ctx)
+			return err
+		},
+		"Webhook.All": func() error {
+			it := client.Webhook.All(ctx)
+			it.Next()
+			return it.Err()
+		},
+		"Webhook.Create": func() error {
+			_, err := client.Webhook.Create(ctx, &WebhookCreateParams{
+				Events: []WebhookEvent{PaymentReserved.Name()},
+				Url:    "https://my-api.com/webhooks",
+			})
+			return err
+		},
+		"Webhook.Find": func() error {
+			_, err := client.Webhook.Find(ctx, webhookId)
+			return err
+		},
+		"Webhook.Update": func() error {
+			_, err := client.Webhook.Update(ctx, webhookId, &WebhookUpdateParams{
+				Events: []WebhookEvent{PaymentReserved.Name()},
+				Url:    "https://my-api.com/webhooks",
+			})
+			return err
+		},
+		"Webhook.Delete": func() error {
+			return client.Webhook.Delete(ctx, webhookId)
+		},
+		"Webhook.PublishTestNotification": func() error {
+			return client.Webhook.PublishTestNotification(ctx, webhookId)
+		},
+		"PaymentPoint.List": func() error {
+			_, err := client.PaymentPoint.List(ctx, nil)
+			return err
+		},
+		"PaymentPoint.All": func() error {
+			it := client.PaymentPoint.All(ctx, nil)
+			it.Next()
+			return it.Err()
+		},
+		"PaymentPoint.Find": func() error {
+			_, err := client.PaymentPoint.Find(ctx, "1f8ed17f-f310-4f40-a7a4-df78185efbdd")
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		err := call()
+		assert.ErrorIs(t, err, context.Canceled, name)
+	}
+	assert.Equal(t, 0, transport.requests)
+}
+
+func TestNewRequest_Uses_Context(t *testing.T) {
+	client := New("test", "test", &Config{URL: TestBaseUrl})
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "v1/webhooks", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "value", req.Context().Value(key{}))
+}
+
+func TestContext_Values_Reach_Logging_And_Middleware(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	var stdout, stderr bytes.Buffer
+	logger := &LeveledLogger{Level: LevelInfo, stdoutOverride: &stdout, stderrOverride: &stderr}
+
+	type tenantKey struct{}
+	var middlewareTenant, requestTenant interface{}
+	middleware := func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, op Operation, req *http.Request) (*http.Response, error) {
+			middlewareTenant = ctx.Value(tenantKey{})
+			requestTenant = req.Context().Value(tenantKey{})
+			return next.Do(ctx, op, req)
+		})
+	}
+
+	client, err := NewWithOptions("test", "test",
+		WithBaseURL(TestBaseUrl),
+		WithLogger(logger),
+		WithMiddleware(middleware),
+	)
+	assert.Nil(t, err)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	ctx = WithLogFields(ctx, "tenant", "acme")
+	ctx = WithRequestOptions(ctx, WithRequestID("order-1234"))
+
+	_, err = client.Webhook.Get(ctx)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "acme", middlewareTenant)
+	assert.Equal(t, "acme", requestTenant)
+
+	line := stdout.String()
+	assert.True(t, strings.HasPrefix(line, "[INFO] MobilePay request completed method=GET path=/v1/webhooks status=200"), line)
+	assert.Contains(t, line, "request_id=order-1234 tenant=acme\n")
+}
+
+func TestWithLogFields_Nested(t *testing.T) {
+	parent := WithLogFields(context.Background(), "tenant", "acme")
+	child := WithLogFields(parent, "user", "42")
+
+	assert.Equal(t, []interface{}{"tenant", "acme"}, logFieldsFromContext(parent))
+	assert.Equal(t, []interface{}{"tenant", "acme", "user", "42"}, logFieldsFromContext(child))
+}
@@ -0,0 +1,207 @@
+package mobilepay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Environment variables read by NewEnvCredentialsProvider by default.
+const (
+	DefaultClientIdEnvVar = "MOBILEPAY_CLIENT_ID"
+	DefaultApiKeyEnvVar   = "MOBILEPAY_API_KEY"
+)
+
+// Credentials authenticate requests to the MobilePay API.
+type Credentials struct {
+	IbmClientId string `json:"ibmClientId"`
+	ApiKey      string `json:"apiKey"`
+}
+
+func (c Credentials) validate() error {
+	if c.IbmClientId == "" {
+		return newArgError("ibmClientId", "cannot be empty")
+	}
+
+	if c.ApiKey == "" {
+		return newArgError("apiKey", "cannot be empty")
+	}
+
+	return nil
+}
+
+// CredentialsProvider returns the credentials used by a Client. It is consulted on every request,
+// so credentials can be rotated without creating a new Client.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsRefresher is implemented by providers that cache credentials. Refresh is called when
+// MobilePay rejects the credentials with 401 Unauthorized, before the request is retried once.
+type CredentialsRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// CredentialsProviderFunc is an adapter to allow the use of ordinary functions as a CredentialsProvider.
+type CredentialsProviderFunc func(ctx context.Context) (Credentials, error)
+
+// Credentials calls f(ctx).
+func (f CredentialsProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// NewEnvCredentialsProvider returns a provider reading the credentials from the given environment
+// variables on every request. Empty names default to MOBILEPAY_CLIENT_ID and MOBILEPAY_API_KEY.
+func NewEnvCredentialsProvider(clientIdVar, apiKeyVar string) CredentialsProvider {
+	if clientIdVar == "" {
+		clientIdVar = DefaultClientIdEnvVar
+	}
+
+	if apiKeyVar == "" {
+		apiKeyVar = DefaultApiKeyEnvVar
+	}
+
+	return CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		return Credentials{
+			IbmClientId: os.Getenv(clientIdVar),
+			ApiKey:      os.Getenv(apiKeyVar),
+		}, nil
+	})
+}
+
+// FileCredentialsProvider reads the credentials from a JSON file like
+//
+//	{"ibmClientId": "...", "apiKey": "..."}
+//
+// The file is watched for changes: it is read again when its modification time or size changes,
+// checked at most once per CheckInterval, and whenever MobilePay rejects the credentials.
+type FileCredentialsProvider struct {
+	// Path is the path of the credentials file.
+	Path string
+
+	// CheckInterval is the minimum time between two checks of the file for changes.
+	// Zero checks the file on every request.
+	CheckInterval time.Duration
+
+	mu          sync.Mutex
+	credentials Credentials
+	modTime     time.Time
+	size        int64
+	checked     time.Time
+	loaded      bool
+}
+
+var (
+	_ CredentialsProvider  = (*FileCredentialsProvider)(nil)
+	_ CredentialsRefresher = (*FileCredentialsProvider)(nil)
+)
+
+// NewFileCredentialsProvider returns a provider reading the credentials from the file at path.
+func NewFileCredentialsProvider(path string) *FileCredentialsProvider {
+	return &FileCredentialsProvider{Path: path}
+}
+
+// Credentials returns the credentials of the file, reading it again if it has changed.
+func (p *FileCredentialsProvider) Credentials(ctx context.Context) (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.loaded && time.Since(p.checked) < p.CheckInterval {
+		return p.credentials, nil
+	}
+
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("read credentials: %w", err)
+	}
+	p.checked = time.Now()
+
+	if p.loaded && info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return p.credentials, nil
+	}
+
+	if err := p.load(info); err != nil {
+		return Credentials{}, err
+	}
+
+	return p.credentials, nil
+}
+
+// Refresh reads the file again.
+func (p *FileCredentialsProvider) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return fmt.Errorf("read credentials: %w", err)
+	}
+	p.checked = time.Now()
+
+	return p.load(info)
+}
+
+func (p *FileCredentialsProvider) load(info os.FileInfo) error {
+	data, err := ioutil.ReadFile(p.Path)
+	if err != nil {
+		return fmt.Errorf("read credentials: %w", err)
+	}
+
+	var credentials Credentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return fmt.Errorf("read credentials: %w", err)
+	}
+
+	p.credentials = credentials
+	p.modTime = info.ModTime()
+	p.size = info.Size()
+	p.loaded = true
+
+	return nil
+}
+
+// credentials returns the credentials of the client's provider.
+func (c *Client) credentials(ctx context.Context) (Credentials, error) {
+	credentials, err := c.credentialsProvider.Credentials(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	if err := credentials.validate(); err != nil {
+		return Credentials{}, err
+	}
+
+	c.redactor.addSecrets(credentials.IbmClientId, credentials.ApiKey)
+
+	return credentials, nil
+}
+
+// setCredentials sets the authentication headers of req.
+func setCredentials(req *http.Request, credentials Credentials) {
+	req.Header.Set(ibmClientIdHeaderKey, credentials.IbmClientId)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", credentials.ApiKey))
+}
+
+// refreshCredentials refreshes the credentials after MobilePay rejected them. It reports whether
+// the refreshed credentials differ from the rejected ones, and so whether to retry the request.
+func (c *Client) refreshCredentials(ctx context.Context, rejected Credentials) (Credentials, bool) {
+	if refresher, ok := c.credentialsProvider.(CredentialsRefresher); ok {
+		if err := refresher.Refresh(ctx); err != nil {
+			c.log(ctx, LevelError, "Refreshing MobilePay credentials failed", "error", err)
+			return Credentials{}, false
+		}
+	}
+
+	credentials, err := c.credentials(ctx)
+	if err != nil {
+		c.log(ctx, LevelError, "Refreshing MobilePay credentials failed", "error", err)
+		return Credentials{}, false
+	}
+
+	return credentials, credentials != rejected
+}
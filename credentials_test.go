@@ -0,0 +1,183 @@
+package mobilepay
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func writeCredentialsFile(t *testing.T, path, clientId, apiKey string, modTime time.Time) {
+	data := []byte(`{"ibmClientId":"` + clientId + `","apiKey":"` + apiKey + `"}`)
+	assert.Nil(t, ioutil.WriteFile(path, data, 0600))
+	assert.Nil(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestEnvCredentialsProvider(t *testing.T) {
+	os.Setenv("TEST_MOBILEPAY_CLIENT_ID", "env-client")
+	os.Setenv(DefaultApiKeyEnvVar, "env-key")
+	defer os.Unsetenv("TEST_MOBILEPAY_CLIENT_ID")
+	defer os.Unsetenv(DefaultApiKeyEnvVar)
+
+	credentials, err := NewEnvCredentialsProvider("TEST_MOBILEPAY_CLIENT_ID", "").Credentials(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{IbmClientId: "env-client", ApiKey: "env-key"}, credentials)
+}
+
+func TestFileCredentialsProvider_Watches_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeCredentialsFile(t, path, "file-client", "file-key", modTime)
+
+	provider := NewFileCredentialsProvider(path)
+
+	credentials, err := provider.Credentials(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{IbmClientId: "file-client", ApiKey: "file-key"}, credentials)
+
+	writeCredentialsFile(t, path, "file-client", "file-key-2", modTime.Add(time.Minute))
+
+	credentials, err = provider.Credentials(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, "file-key-2", credentials.ApiKey)
+
+	assert.Nil(t, os.Remove(path))
+	_, err = provider.Credentials(context.TODO())
+	assert.Error(t, err)
+}
+
+func TestFileCredentialsProvider_Check_Interval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeCredentialsFile(t, path, "file-client", "file-key", modTime)
+
+	provider := &FileCredentialsProvider{Path: path, CheckInterval: time.Hour}
+
+	_, err := provider.Credentials(context.TODO())
+	assert.Nil(t, err)
+
+	writeCredentialsFile(t, path, "file-client", "file-key-2", modTime.Add(time.Minute))
+
+	credentials, err := provider.Credentials(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, "file-key", credentials.ApiKey, "the file should not be checked before the interval has passed")
+
+	assert.Nil(t, provider.Refresh(context.TODO()))
+	credentials, err = provider.Credentials(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, "file-key-2", credentials.ApiKey)
+}
+
+func TestCredentialsProvider_Consulted_On_Every_Request(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	for _, key := range []string{"key-1", "key-2"} {
+		gock.New(TestBaseUrl).
+			Get("/v1/webhooks").
+			MatchHeader("Authorization", "Bearer "+key).
+			MatchHeader("x-ibm-client-id", "client").
+			Reply(200).
+			JSON(map[string]interface{}{"webhooks": []interface{}{}})
+	}
+
+	key := "key-1"
+	provider := CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		return Credentials{IbmClientId: "client", ApiKey: key}, nil
+	})
+
+	client, err := NewWithOptions("", "", WithBaseURL(TestBaseUrl), WithCredentialsProvider(provider))
+	assert.Nil(t, err)
+
+	_, err = client.Webhook.Get(context.TODO())
+	assert.Nil(t, err)
+
+	key = "key-2"
+	_, err = client.Webhook.Get(context.TODO())
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+}
+
+func TestCredentialsProvider_Refresh_On_Unauthorized(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	params := &RefundParams{
+		Amount:         100,
+		IdempotencyKey: "7576910d-9789-4fef-a72e-877d89afec94",
+		PaymentId:      "211444eb-1c4e-4194-a58f-905d97877cc5",
+	}
+
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		MatchHeader("Authorization", "Bearer file-key").
+		Reply(401)
+
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		MatchHeader("Authorization", "Bearer file-key-rotated").
+		JSON(params).
+		Reply(200).
+		JSON(map[string]interface{}{"paymentId": params.PaymentId, "amount": 100})
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeCredentialsFile(t, path, "file-client", "file-key", modTime)
+
+	provider := &FileCredentialsProvider{Path: path, CheckInterval: time.Hour}
+	client, err := NewWithOptions("", "", WithBaseURL(TestBaseUrl), WithCredentialsProvider(provider))
+	assert.Nil(t, err)
+
+	_, err = provider.Credentials(context.TODO())
+	assert.Nil(t, err)
+	writeCredentialsFile(t, path, "file-client", "file-key-rotated", modTime.Add(time.Minute))
+
+	refund, err := client.Payment.Refund.Create(context.TODO(), params)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, refund.Amount)
+	assert.True(t, gock.IsDone())
+}
+
+func TestCredentialsProvider_Unchanged_Credentials_Are_Not_Retried(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(401)
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	provider := CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		return Credentials{IbmClientId: "client", ApiKey: "key"}, nil
+	})
+
+	client, err := NewWithOptions("", "", WithBaseURL(TestBaseUrl), WithCredentialsProvider(provider))
+	assert.Nil(t, err)
+
+	_, err = client.Webhook.Get(context.TODO())
+	assert.True(t, IsAuthError(err))
+	assert.True(t, gock.IsPending())
+}
+
+func TestCredentialsProvider_Invalid_Credentials(t *testing.T) {
+	transport := &countingTransport{}
+	provider := NewEnvCredentialsProvider("TEST_MOBILEPAY_UNSET_CLIENT_ID", "TEST_MOBILEPAY_UNSET_API_KEY")
+
+	client, err := NewWithOptions("", "",
+		WithBaseURL(TestBaseUrl),
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithCredentialsProvider(provider),
+	)
+	assert.Nil(t, err)
+
+	_, err = client.Webhook.Get(context.TODO())
+	assert.Error(t, err)
+	assert.Equal(t, 0, transport.requests)
+}
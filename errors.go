@@ -1,19 +1,193 @@
 package mobilepay
 
 import (
+	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
 )
 
 var (
-	ErrMissingVerifierProperties = errors.New("missing required verifier properties signature or webhook url")
+	ErrMissingVerifierProperties   = errors.New("missing required verifier properties signature or webhook url")
+	ErrInvalidBaseURL              = errors.New("missing or invalid base url")
+	ErrInvalidStateTransition      = errors.New("invalid payment state transition")
+	ErrInvalidWebhookSignature     = errors.New("invalid webhook signature")
+	ErrInvalidWebhookNotification  = errors.New("invalid webhook notification")
+	ErrUnknownMerchant             = errors.New("unknown merchant")
+	ErrTestNotificationNotReceived = errors.New("test notification not received")
 )
 
+// Errors matching an ErrorResponse by its status code, e.g. errors.Is(err, ErrNotFound).
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServerError  = errors.New("server error")
+)
+
+// ErrorCode is the code of the ConflictError returned by MobilePay when a request fails.
+// An ErrorCode is an error itself, so errors.Is(err, ErrorCodeAmountTooLarge) reports whether
+// the request failed with that code.
+type ErrorCode string
+
+// Error codes returned by the MobilePay API.
+const (
+	ErrorCodeInvalidRequest          ErrorCode = "invalid_request"
+	ErrorCodeUnauthorized            ErrorCode = "unauthorized"
+	ErrorCodeProcessingError         ErrorCode = "processing_error"
+	ErrorCodePaymentNotFound         ErrorCode = "payment_not_found"
+	ErrorCodePaymentPointNotFound    ErrorCode = "payment_point_not_found"
+	ErrorCodeWebhookNotFound         ErrorCode = "webhook_not_found"
+	ErrorCodeAmountTooLarge          ErrorCode = "amount_too_large"
+	ErrorCodePaymentStateInvalid     ErrorCode = "payment_state_invalid"
+	ErrorCodeRefundAmountTooLarge    ErrorCode = "refund_amount_too_large"
+	ErrorCodeDuplicateIdempotencyKey ErrorCode = "duplicate_idempotency_key"
+)
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Code returns the code of the ConflictError of the response, or an empty code if there is none.
+func (r *ErrorResponse) Code() ErrorCode {
+	if r.Conflict == nil {
+		return ""
+	}
+
+	return ErrorCode(r.Conflict.Code)
+}
+
+// CorrelationID returns the correlation id MobilePay assigned to the failed request.
+// Quote it when contacting MobilePay support.
+func (r *ErrorResponse) CorrelationID() string {
+	if r.Conflict != nil && r.Conflict.CorrelationID != "" {
+		return r.Conflict.CorrelationID
+	}
+
+	if r.Response != nil {
+		return r.Response.Header.Get(correlationIdHeader)
+	}
+
+	return ""
+}
+
+// Is makes errors.Is match an ErrorResponse against the status code errors of this package
+// (ErrNotFound, ErrConflict, ...) and against an ErrorCode.
+func (r *ErrorResponse) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return r.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return r.StatusCode == http.StatusUnauthorized || r.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return r.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return r.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return r.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return r.StatusCode >= 500
+	}
+
+	if code, ok := target.(ErrorCode); ok {
+		return r.Code() == code
+	}
+
+	return false
+}
+
+// Unwrap returns the ConflictError of the response, so it can be retrieved with errors.As.
+func (r *ErrorResponse) Unwrap() error {
+	if r.Conflict == nil {
+		return nil
+	}
+
+	return r.Conflict
+}
+
+// IsNotFound reports whether err is caused by a resource that does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict reports whether err is caused by a request conflicting with the state of a resource,
+// e.g. capturing a payment that is not reserved.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsRateLimited reports whether err is caused by MobilePay rate limiting the requests.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsAuthError reports whether err is caused by an invalid api key or client id.
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsRetryable reports whether the request that caused err may succeed if it is sent again:
+// network errors, timeouts, rate limiting and server errors.
+func IsRetryable(err error) bool {
+	var errorResponse *ErrorResponse
+	if errors.As(err, &errorResponse) {
+		return isRetryableStatus(errorResponse.StatusCode)
+	}
+
+	return isTransientError(err)
+}
+
+// isTransientError reports whether err is a network error or a timeout that may not happen again.
+// Every error returned by http.Client is a *url.Error, which implements net.Error, so the cause
+// is inspected instead. Cancellation, unsupported schemes and certificate problems are permanent.
+func isTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certificateInvalid x509.CertificateInvalidError
+	if errors.As(err, &unknownAuthority) || errors.As(err, &hostnameErr) || errors.As(err, &certificateInvalid) {
+		return false
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			// the connection was closed by the server, e.g. an idle connection that timed out.
+			return true
+		}
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests ||
+		(statusCode >= 500 && statusCode != http.StatusNotImplemented)
+}
+
 // ArgError is an error that represents an error with an input to mobilepay app payment. It
 // identifies the argument and the cause (if possible).
 type ArgError struct {
-	arg    string
-	reason string
+	// Arg is the name of the invalid argument or field, e.g. "amount".
+	Arg string
+	// Reason describes why the argument is invalid.
+	Reason string
 }
 
 var _ error = &ArgError{}
@@ -21,11 +195,58 @@ var _ error = &ArgError{}
 // newArgError creates an InputError.
 func newArgError(arg, reason string) *ArgError {
 	return &ArgError{
-		arg:    arg,
-		reason: reason,
+		Arg:    arg,
+		Reason: reason,
 	}
 }
 
 func (e *ArgError) Error() string {
-	return fmt.Sprintf("%s is invalid because %s", e.arg, e.reason)
+	return fmt.Sprintf("%s is invalid because %s", e.Arg, e.Reason)
+}
+
+// ValidationError reports every problem found when validating the params of a request.
+// errors.As(err, &argErr) finds the first of them.
+type ValidationError struct {
+	Errors []*ArgError
+}
+
+var _ error = &ValidationError{}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		reasons[i] = err.Error()
+	}
+
+	return "validation failed: " + strings.Join(reasons, "; ")
+}
+
+// As sets target to the first ArgError if target is a **ArgError.
+func (e *ValidationError) As(target interface{}) bool {
+	argErr, ok := target.(**ArgError)
+	if !ok || len(e.Errors) == 0 {
+		return false
+	}
+
+	*argErr = e.Errors[0]
+
+	return true
+}
+
+// validator collects the problems found while validating params.
+type validator struct {
+	errors []*ArgError
+}
+
+func (v *validator) add(arg, reason string) {
+	v.errors = append(v.errors, newArgError(arg, reason))
+}
+
+// err returns a ValidationError with the collected problems, or nil if there are none.
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Errors: v.errors}
 }
@@ -1,8 +1,17 @@
 package mobilepay
 
 import (
-	"github.com/stretchr/testify/assert"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestArgError_Error(t *testing.T) {
@@ -11,3 +20,128 @@ func TestArgError_Error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, expected, err.Error())
 }
+
+func newErrorResponse(statusCode int, conflict *ConflictError) *ErrorResponse {
+	return &ErrorResponse{
+		Response: &http.Response{
+			StatusCode: statusCode,
+			Header:     http.Header{},
+			Request: &http.Request{
+				Method: http.MethodPost,
+				URL:    &url.URL{Scheme: "https", Host: "api.mobilepay.dk", Path: "/v1/payments/1/capture"},
+			},
+		},
+		Conflict:   conflict,
+		StatusCode: statusCode,
+	}
+}
+
+func TestErrorResponse_Is(t *testing.T) {
+	var err error = newErrorResponse(http.StatusConflict, &ConflictError{Code: "amount_too_large"})
+
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorIs(t, err, ErrorCodeAmountTooLarge)
+	assert.ErrorIs(t, fmt.Errorf("capture failed: %w", err), ErrorCodeAmountTooLarge)
+	assert.False(t, errors.Is(err, ErrorCodePaymentStateInvalid))
+	assert.False(t, errors.Is(err, ErrNotFound))
+
+	assert.True(t, IsConflict(err))
+	assert.False(t, IsNotFound(err))
+	assert.False(t, IsRetryable(err))
+	assert.Equal(t, ErrorCodeAmountTooLarge, err.(*ErrorResponse).Code())
+}
+
+func TestErrorResponse_As(t *testing.T) {
+	err := fmt.Errorf("capture failed: %w", newErrorResponse(http.StatusConflict, &ConflictError{Code: "amount_too_large", Message: "Cannot capture a larger amount than is reserved."}))
+
+	var conflict *ConflictError
+	assert.True(t, errors.As(err, &conflict))
+	assert.Equal(t, "amount_too_large", conflict.Code)
+
+	var errorResponse *ErrorResponse
+	assert.True(t, errors.As(err, &errorResponse))
+	assert.Equal(t, http.StatusConflict, errorResponse.StatusCode)
+
+	assert.False(t, errors.As(newErrorResponse(http.StatusInternalServerError, nil), &conflict))
+}
+
+func TestErrorResponse_Predicates(t *testing.T) {
+	tests := []struct {
+		statusCode  int
+		notFound    bool
+		rateLimited bool
+		auth        bool
+		retryable   bool
+	}{
+		{http.StatusBadRequest, false, false, false, false},
+		{http.StatusUnauthorized, false, false, true, false},
+		{http.StatusForbidden, false, false, true, false},
+		{http.StatusNotFound, true, false, false, false},
+		{http.StatusTooManyRequests, false, true, false, true},
+		{http.StatusInternalServerError, false, false, false, true},
+		{http.StatusNotImplemented, false, false, false, false},
+		{http.StatusServiceUnavailable, false, false, false, true},
+	}
+
+	for _, tt := range tests {
+		err := newErrorResponse(tt.statusCode, nil)
+		assert.Equal(t, tt.notFound, IsNotFound(err), "IsNotFound %d", tt.statusCode)
+		assert.Equal(t, tt.rateLimited, IsRateLimited(err), "IsRateLimited %d", tt.statusCode)
+		assert.Equal(t, tt.auth, IsAuthError(err), "IsAuthError %d", tt.statusCode)
+		assert.Equal(t, tt.retryable, IsRetryable(err), "IsRetryable %d", tt.statusCode)
+	}
+
+	assert.ErrorIs(t, newErrorResponse(http.StatusBadGateway, nil), ErrServerError)
+	assert.ErrorIs(t, newErrorResponse(http.StatusBadRequest, nil), ErrBadRequest)
+	assert.True(t, IsRetryable(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.False(t, IsRetryable(errors.New("boom")))
+
+	transportErrors := []struct {
+		err       error
+		retryable bool
+	}{
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, true},
+		{io.EOF, true},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{errors.New(`unsupported protocol scheme "ftp"`), false},
+		{x509.UnknownAuthorityError{}, false},
+		{x509.CertificateInvalidError{Reason: x509.Expired}, false},
+		{&net.OpError{Op: "remote error", Err: x509.HostnameError{Host: "api.mobilepay.dk"}}, false},
+	}
+
+	for _, tt := range transportErrors {
+		err := &url.Error{Op: "Post", URL: "https://api.mobilepay.dk/v1/payments", Err: tt.err}
+		assert.Equal(t, tt.retryable, IsRetryable(err), "IsRetryable %v", tt.err)
+	}
+
+	_, err := http.Get("ftp://api.mobilepay.dk/v1/payments")
+	assert.False(t, IsRetryable(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1:1/v1/payments", nil)
+	_, err = http.DefaultClient.Do(req)
+	assert.False(t, IsRetryable(err))
+	assert.False(t, IsNotFound(nil))
+}
+
+func TestErrorResponse_Error(t *testing.T) {
+	err := newErrorResponse(http.StatusConflict, &ConflictError{
+		Code:          "amount_too_large",
+		Message:       "Cannot capture a larger amount than is reserved.",
+		CorrelationID: "d503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a",
+	})
+
+	expected := "POST https://api.mobilepay.dk/v1/payments/1/capture: 409 amount_too_large: Cannot capture a larger amount than is reserved. (correlation id: d503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a)"
+	assert.Equal(t, expected, err.Error())
+
+	err = newErrorResponse(http.StatusInternalServerError, nil)
+	err.Message = "Backend error"
+	err.Response.Header.Set("CorrelationId", "f503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a")
+	assert.Equal(t, "f503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a", err.CorrelationID())
+	assert.Equal(t, "POST https://api.mobilepay.dk/v1/payments/1/capture: 500 Backend error (correlation id: f503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a)", err.Error())
+
+	assert.Equal(t, "404 ", (&ErrorResponse{StatusCode: http.StatusNotFound}).Error())
+}
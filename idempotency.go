@@ -0,0 +1,148 @@
+package mobilepay
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Resources passed to an IdempotencyKeyProvider.
+const (
+	IdempotencyResourcePayment = "payment"
+	IdempotencyResourceRefund  = "refund"
+)
+
+// IdempotencyKeyProvider provides the idempotency key of a payment or refund that is created
+// without one. resource is either IdempotencyResourcePayment or IdempotencyResourceRefund,
+// paymentId is the id of the refunded payment of a refund and empty for a payment, and
+// reference is the Reference of the payment or refund. The key must be a valid UUID.
+type IdempotencyKeyProvider interface {
+	IdempotencyKey(ctx context.Context, resource, paymentId, reference string) (string, error)
+}
+
+// IdempotencyKeyProviderFunc is an adapter to allow the use of ordinary functions as
+// an IdempotencyKeyProvider.
+type IdempotencyKeyProviderFunc func(ctx context.Context, resource, paymentId, reference string) (string, error)
+
+// IdempotencyKey calls f(ctx, resource, paymentId, reference).
+func (f IdempotencyKeyProviderFunc) IdempotencyKey(ctx context.Context, resource, paymentId, reference string) (string, error) {
+	return f(ctx, resource, paymentId, reference)
+}
+
+// RandomIdempotencyKeys provides a new random UUID (version 4) for every payment and refund.
+// Automatic retries reuse the key, but a payment created again after a crash gets a new key.
+var RandomIdempotencyKeys IdempotencyKeyProvider = IdempotencyKeyProviderFunc(
+	func(ctx context.Context, resource, paymentId, reference string) (string, error) {
+		return NewIdempotencyKey()
+	},
+)
+
+// referenceIdempotencyKeys derives idempotency keys from the reference of a payment or refund.
+type referenceIdempotencyKeys struct {
+	namespace [16]byte
+}
+
+// NewReferenceIdempotencyKeyProvider returns an IdempotencyKeyProvider that derives the key
+// from the resource, the refunded payment and the reference using a name-based UUID (version 5)
+// in the given namespace. Creating a payment or refund again with the same reference, e.g. after
+// a crash, reuses the same key so MobilePay does not create it twice. References must therefore
+// be unique among the payments and among the refunds of a payment.
+func NewReferenceIdempotencyKeyProvider(namespace string) (IdempotencyKeyProvider, error) {
+	if !isUUID(namespace) {
+		return nil, newArgError("namespace", "it must be a valid UUID")
+	}
+
+	b, err := hex.DecodeString(strings.Replace(namespace, "-", "", -1))
+	if err != nil {
+		return nil, newArgError("namespace", err.Error())
+	}
+
+	p := &referenceIdempotencyKeys{}
+	copy(p.namespace[:], b)
+
+	return p, nil
+}
+
+func (p *referenceIdempotencyKeys) IdempotencyKey(ctx context.Context, resource, paymentId, reference string) (string, error) {
+	if reference == "" {
+		return "", newArgError("reference", "it is required to derive an idempotency key")
+	}
+
+	name := resource + ":" + reference
+	if paymentId != "" {
+		name = resource + ":" + paymentId + ":" + reference
+	}
+
+	h := sha1.New()
+	h.Write(p.namespace[:])
+	h.Write([]byte(name))
+
+	var uuid [16]byte
+	copy(uuid[:], h.Sum(nil))
+	uuid[6] = (uuid[6] & 0x0f) | 0x50
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return formatUUID(uuid), nil
+}
+
+// NewIdempotencyKey returns a new random UUID (version 4) that can be used as an idempotency key.
+func NewIdempotencyKey() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return formatUUID(uuid), nil
+}
+
+func formatUUID(uuid [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
+// isUUID reports whether s is a UUID in its canonical textual form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// resolveIdempotencyKey returns key, the key set with WithIdempotencyKey, or a key from the client's
+// IdempotencyKeyProvider, in that order. paymentId is the id of the refunded payment of a refund.
+// The key is not validated here, so that its problems are reported together with those of the
+// other params.
+func (c *Client) resolveIdempotencyKey(ctx context.Context, resource, paymentId, reference, key string) (string, error) {
+	if options := requestOptionsFromContext(ctx); key == "" && options != nil {
+		key = options.idempotencyKey
+	}
+
+	if key == "" && c.idempotencyKeyProvider != nil {
+		generated, err := c.idempotencyKeyProvider.IdempotencyKey(ctx, resource, paymentId, reference)
+		if err != nil {
+			return "", err
+		}
+		key = generated
+	}
+
+	return key, nil
+}
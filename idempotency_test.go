@@ -0,0 +1,159 @@
+package mobilepay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// idempotencyKeyMatcher captures the idempotency key sent in the request body.
+func idempotencyKeyMatcher(key *string) gock.MatchFunc {
+	return func(req *http.Request, ereq *gock.Request) (bool, error) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return false, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		var params struct {
+			IdempotencyKey string `json:"idempotencyKey"`
+		}
+		if err := json.Unmarshal(body, &params); err != nil {
+			return false, err
+		}
+		*key = params.IdempotencyKey
+
+		return true, nil
+	}
+}
+
+func TestIdempotency_Missing_Key(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments").
+		Reply(200).
+		JSON(map[string]string{})
+
+	client := New("test", "test", &Config{URL: TestBaseUrl, Logger: &LeveledLogger{Level: LevelNull}})
+
+	params := validPaymentParams()
+	params.IdempotencyKey = ""
+
+	payment, err := client.Payment.Create(context.TODO(), params)
+	assert.Equal(t, []string{"idempotencyKey"}, validationArgs(t, err))
+	assert.Equal(t, "validation failed: idempotencyKey is invalid because cannot be empty", err.Error())
+	assert.Nil(t, payment)
+	assert.True(t, gock.IsPending())
+}
+
+func TestIdempotency_Invalid_Key(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		Reply(200).
+		JSON(map[string]string{})
+
+	client := New("test", "test", &Config{URL: TestBaseUrl, Logger: &LeveledLogger{Level: LevelNull}})
+
+	params := validRefundParams()
+	params.IdempotencyKey = "order-1"
+
+	refund, err := client.Payment.Refund.Create(context.TODO(), params)
+	assert.Equal(t, []string{"idempotencyKey"}, validationArgs(t, err))
+	assert.Equal(t, "validation failed: idempotencyKey is invalid because it must be a valid UUID", err.Error())
+	assert.Nil(t, refund)
+	assert.True(t, gock.IsPending())
+}
+
+func TestIdempotency_Generated_Key(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	var sent string
+	gock.New(TestBaseUrl).
+		Post("/v1/payments").
+		AddMatcher(idempotencyKeyMatcher(&sent)).
+		Reply(200).
+		JSON(map[string]string{"paymentId": "186d2b31-ff25-4414-9fd1-bfe9807fa8b7"})
+
+	client, err := NewWithOptions("test", "test", WithBaseURL(TestBaseUrl), WithGeneratedIdempotencyKeys())
+	assert.Nil(t, err)
+
+	params := &PaymentParams{
+		Amount:         100,
+		PaymentPointId: "1f8ed17f-f310-4f40-a7a4-df78185efbdd",
+		RedirectUri:    "app://callback",
+		Reference:      "order-1",
+	}
+	_, err = client.Payment.Create(context.TODO(), params)
+
+	assert.Nil(t, err)
+	assert.True(t, isUUID(sent))
+	assert.Equal(t, "4", sent[14:15])
+	assert.Equal(t, sent, params.IdempotencyKey)
+}
+
+func TestIdempotency_Provider_Error(t *testing.T) {
+	providerErr := errors.New("key store unavailable")
+	provider := IdempotencyKeyProviderFunc(func(ctx context.Context, resource, paymentId, reference string) (string, error) {
+		return "", providerErr
+	})
+
+	client, err := NewWithOptions("test", "test", WithIdempotencyKeyProvider(provider), WithLogger(&LeveledLogger{Level: LevelNull}))
+	assert.Nil(t, err)
+
+	_, err = client.Payment.Refund.Create(context.TODO(), &RefundParams{Amount: 100, Reference: "refund-1"})
+	assert.ErrorIs(t, err, providerErr)
+}
+
+func TestReferenceIdempotencyKeyProvider(t *testing.T) {
+	provider, err := NewReferenceIdempotencyKeyProvider("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	assert.Nil(t, err)
+
+	ctx := context.TODO()
+
+	key, err := provider.IdempotencyKey(ctx, IdempotencyResourcePayment, "", "order-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc1088e-0f1c-5064-85fd-9002b081ca53", key)
+
+	again, _ := provider.IdempotencyKey(ctx, IdempotencyResourcePayment, "", "order-1")
+	assert.Equal(t, key, again)
+
+	refundKey, _ := provider.IdempotencyKey(ctx, IdempotencyResourceRefund, "211444eb-1c4e-4194-a58f-905d97877cc5", "order-1")
+	assert.NotEqual(t, key, refundKey)
+
+	otherRefundKey, _ := provider.IdempotencyKey(ctx, IdempotencyResourceRefund, "186d2b31-ff25-4414-9fd1-bfe9807fa8b7", "order-1")
+	assert.NotEqual(t, refundKey, otherRefundKey)
+
+	_, err = provider.IdempotencyKey(ctx, IdempotencyResourcePayment, "", "")
+	assert.IsType(t, &ArgError{}, err)
+
+	_, err = NewReferenceIdempotencyKeyProvider("not-a-uuid")
+	assert.IsType(t, &ArgError{}, err)
+}
+
+func TestNewIdempotencyKey(t *testing.T) {
+	key, err := NewIdempotencyKey()
+	assert.Nil(t, err)
+	assert.True(t, isUUID(key))
+
+	other, _ := NewIdempotencyKey()
+	assert.NotEqual(t, key, other)
+}
+
+func TestIsUUID(t *testing.T) {
+	assert.True(t, isUUID("7347ba06-95c5-4181-82e5-7c7a23609a0e"))
+	assert.True(t, isUUID("7347BA06-95C5-4181-82E5-7C7A23609A0E"))
+	assert.False(t, isUUID(""))
+	assert.False(t, isUUID("7347ba0695c5418182e57c7a23609a0e"))
+	assert.False(t, isUUID("7347ba06-95c5-4181-82e5-7c7a23609a0g"))
+	assert.False(t, isUUID("7347ba06_95c5-4181-82e5-7c7a23609a0e"))
+}
@@ -0,0 +1,159 @@
+package mobilepay
+
+import "context"
+
+// page is a single page of results returned by a list endpoint.
+type page struct {
+	items          []interface{}
+	nextPageNumber int
+}
+
+// pageFetcher fetches the page with the given page number.
+type pageFetcher func(ctx context.Context, pageNumber int) (*page, error)
+
+type pageResult struct {
+	page *page
+	err  error
+}
+
+// iter is the generic iterator used by the typed iterators of this package.
+// Pages are fetched lazily when the items of the previous page have been consumed.
+type iter struct {
+	ctx      context.Context
+	fetch    pageFetcher
+	prefetch bool
+
+	items    []interface{}
+	current  interface{}
+	nextPage int
+	err      error
+	pending  chan pageResult
+}
+
+func newIter(ctx context.Context, firstPage int, prefetch bool, fetch pageFetcher) *iter {
+	if firstPage < 1 {
+		firstPage = 1
+	}
+
+	return &iter{
+		ctx:      ctx,
+		fetch:    fetch,
+		prefetch: prefetch,
+		nextPage: firstPage,
+	}
+}
+
+// Next advances the iterator to the next item. It returns false when there are no more items,
+// when a page could not be fetched or when the context is done. Check Err afterwards.
+func (it *iter) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	for len(it.items) == 0 {
+		if it.nextPage == 0 {
+			return false
+		}
+
+		pageNumber := it.nextPage
+		p, err := it.fetchPage(pageNumber)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.items = p.items
+		it.nextPage = p.nextPageNumber
+
+		// guard against looping forever if the API does not advance the page number.
+		if it.nextPage <= pageNumber {
+			it.nextPage = 0
+		}
+
+		if it.prefetch && it.nextPage != 0 {
+			it.startPrefetch(it.nextPage)
+		}
+	}
+
+	it.current = it.items[0]
+	it.items = it.items[1:]
+
+	return true
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *iter) Err() error {
+	return it.err
+}
+
+func (it *iter) fetchPage(pageNumber int) (*page, error) {
+	if it.pending != nil {
+		result := <-it.pending
+		it.pending = nil
+
+		return result.page, result.err
+	}
+
+	return it.fetch(it.ctx, pageNumber)
+}
+
+// startPrefetch fetches the given page in the background. The channel is buffered so
+// the goroutine never blocks, even if the iterator is abandoned.
+func (it *iter) startPrefetch(pageNumber int) {
+	it.pending = make(chan pageResult, 1)
+
+	go func(pending chan<- pageResult) {
+		p, err := it.fetch(it.ctx, pageNumber)
+		pending <- pageResult{page: p, err: err}
+	}(it.pending)
+}
+
+// PaymentIterator iterates over payments, fetching pages lazily.
+type PaymentIterator struct {
+	*iter
+}
+
+// Current returns the payment the iterator currently points to.
+func (it *PaymentIterator) Current() Payment {
+	payment, _ := it.current.(Payment)
+	return payment
+}
+
+// RefundIterator iterates over refunds, fetching pages lazily.
+type RefundIterator struct {
+	*iter
+}
+
+// Current returns the refund the iterator currently points to.
+func (it *RefundIterator) Current() Refund {
+	refund, _ := it.current.(Refund)
+	return refund
+}
+
+// PaymentPointIterator iterates over payment points, fetching pages lazily.
+type PaymentPointIterator struct {
+	*iter
+}
+
+// Current returns the payment point the iterator currently points to.
+func (it *PaymentPointIterator) Current() PaymentPoint {
+	paymentPoint, _ := it.current.(PaymentPoint)
+	return paymentPoint
+}
+
+// WebhookIterator iterates over webhooks. The webhooks API is not paginated, so all
+// webhooks are fetched at once when Next is called the first time.
+type WebhookIterator struct {
+	*iter
+}
+
+// Current returns the webhook the iterator currently points to.
+func (it *WebhookIterator) Current() Webhook {
+	webhook, _ := it.current.(Webhook)
+	return webhook
+}
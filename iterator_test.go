@@ -0,0 +1,179 @@
+package mobilepay
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func mockPaymentsPage(t *testing.T, pageNumber, nextPageNumber int) {
+	testdata, err := ioutil.ReadFile("testdata/list_payments.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testdata = bytes.Replace(testdata, []byte("PAGE_SIZE"), []byte(strconv.Itoa(3)), 1)
+	testdata = bytes.Replace(testdata, []byte("NEXT_PAGE_NUMBER"), []byte(strconv.Itoa(nextPageNumber)), 1)
+
+	gock.New(TestBaseUrl).
+		Get("/v1/payments").
+		MatchParam("pageNumber", strconv.Itoa(pageNumber)).
+		MatchParam("pageSize", "3").
+		Reply(200).
+		JSON(testdata)
+}
+
+func TestPaymentIterator(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	mockPaymentsPage(t, 1, 2)
+	mockPaymentsPage(t, 2, 0)
+
+	client := New("test", "test", config)
+
+	it := client.Payment.All(context.TODO(), ListOptions{PageSize: 3})
+
+	count := 0
+	for it.Next() {
+		assert.NotEmpty(t, it.Current().PaymentId)
+		count++
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 6, count)
+	assert.True(t, gock.IsDone())
+}
+
+func TestPaymentIterator_Prefetch(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	mockPaymentsPage(t, 2, 3)
+	mockPaymentsPage(t, 3, 0)
+
+	client := New("test", "test", config)
+
+	it := client.Payment.All(context.TODO(), ListOptions{PageSize: 3, PageNumber: 2, Prefetch: true})
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 6, count)
+	assert.True(t, gock.IsDone())
+}
+
+func TestPaymentIterator_Error(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	mockPaymentsPage(t, 1, 2)
+
+	gock.New(TestBaseUrl).
+		Get("/v1/payments").
+		MatchParam("pageNumber", "2").
+		Reply(500).
+		JSON("Backend error")
+
+	client := New("test", "test", config)
+
+	it := client.Payment.All(context.TODO(), ListOptions{PageSize: 3})
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	assert.Equal(t, 3, count)
+	assert.IsType(t, &ErrorResponse{}, it.Err())
+	assert.False(t, it.Next())
+}
+
+func TestPaymentIterator_Context_Cancelled(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	mockPaymentsPage(t, 1, 2)
+	mockPaymentsPage(t, 2, 0)
+
+	client := New("test", "test", config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := client.Payment.All(ctx, ListOptions{PageSize: 3})
+
+	assert.True(t, it.Next())
+	cancel()
+
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), context.Canceled)
+	assert.True(t, gock.IsPending())
+}
+
+func TestRefundIterator(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	testdata, err := ioutil.ReadFile("testdata/list_refunds.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testdata = bytes.Replace(testdata, []byte("PAGE_SIZE"), []byte(strconv.Itoa(10)), 1)
+	testdata = bytes.Replace(testdata, []byte("NEXT_PAGE_NUMBER"), []byte(strconv.Itoa(0)), 1)
+
+	gock.New(TestBaseUrl).
+		Get("/v1/refunds").
+		MatchParam("pageNumber", "1").
+		MatchParam("pageSize", "10").
+		MatchParam("paymentId", "211444eb-1c4e-4194-a58f-905d97877cc5").
+		Reply(200).
+		JSON(testdata)
+
+	client := New("test", "test", config)
+
+	opts := &RefundsListOptions{
+		ListOptions: ListOptions{PageSize: 10},
+		PaymentId:   "211444eb-1c4e-4194-a58f-905d97877cc5",
+	}
+
+	it := client.Payment.Refund.All(context.TODO(), opts)
+
+	var amounts []int
+	for it.Next() {
+		amounts = append(amounts, it.Current().Amount)
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []int{100, 200, 300, 400, 500}, amounts)
+	assert.Equal(t, 0, opts.PageNumber)
+}
+
+func TestWebhookIterator(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	testdata, err := ioutil.ReadFile("testdata/list_webhooks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(testdata)
+
+	client := New("test", "test", config)
+
+	it := client.Webhook.All(context.TODO())
+
+	count := 0
+	for it.Next() {
+		assert.NotEmpty(t, it.Current().WebhookId)
+		count++
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 2, count)
+}
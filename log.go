@@ -1,9 +1,12 @@
 package mobilepay
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -126,3 +129,114 @@ type LeveledLoggerInterface interface {
 	// Warnf logs a warning message using Printf conventions.
 	Warnf(format string, v ...interface{})
 }
+
+// StructuredLogger is implemented by loggers that accept key/value pairs as fields, such as
+// log/slog. When Client.Logger implements StructuredLogger, the client logs every request
+// with its method, path, status, latency, attempt, correlation id and payment id as fields.
+type StructuredLogger interface {
+	// Log logs msg at the given level. keysAndValues holds alternating keys and values.
+	Log(ctx context.Context, level Level, msg string, keysAndValues ...interface{})
+}
+
+// StructuredLoggerFunc is an adapter to allow the use of ordinary functions as a StructuredLogger,
+// e.g. to forward log records to a key/value logger like zap's SugaredLogger or logr.
+type StructuredLoggerFunc func(ctx context.Context, level Level, msg string, keysAndValues ...interface{})
+
+// Log calls f(ctx, level, msg, keysAndValues...).
+func (f StructuredLoggerFunc) Log(ctx context.Context, level Level, msg string, keysAndValues ...interface{}) {
+	f(ctx, level, msg, keysAndValues...)
+}
+
+// NewStructuredLeveledLogger returns a LeveledLoggerInterface, that can be used as Client.Logger,
+// backed by the given StructuredLogger. Printf style messages are logged without fields.
+func NewStructuredLeveledLogger(logger StructuredLogger) LeveledLoggerInterface {
+	return &structuredLeveledLogger{logger: logger}
+}
+
+type structuredLeveledLogger struct {
+	logger StructuredLogger
+}
+
+func (l *structuredLeveledLogger) Log(ctx context.Context, level Level, msg string, keysAndValues ...interface{}) {
+	l.logger.Log(ctx, level, msg, keysAndValues...)
+}
+
+func (l *structuredLeveledLogger) Debugf(format string, v ...interface{}) {
+	l.logger.Log(context.Background(), LevelDebug, fmt.Sprintf(format, v...))
+}
+
+func (l *structuredLeveledLogger) Errorf(format string, v ...interface{}) {
+	l.logger.Log(context.Background(), LevelError, fmt.Sprintf(format, v...))
+}
+
+func (l *structuredLeveledLogger) Infof(format string, v ...interface{}) {
+	l.logger.Log(context.Background(), LevelInfo, fmt.Sprintf(format, v...))
+}
+
+func (l *structuredLeveledLogger) Warnf(format string, v ...interface{}) {
+	l.logger.Log(context.Background(), LevelWarn, fmt.Sprintf(format, v...))
+}
+
+type logFieldsContextKey struct{}
+
+// WithLogFields returns a copy of ctx carrying key/value pairs that are added to every log line
+// of requests made with the context, e.g. a tenant or a trace id.
+func WithLogFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	parent := logFieldsFromContext(ctx)
+
+	fields := make([]interface{}, 0, len(parent)+len(keysAndValues))
+	fields = append(fields, parent...)
+	fields = append(fields, keysAndValues...)
+
+	return context.WithValue(ctx, logFieldsContextKey{}, fields)
+}
+
+// logFieldsFromContext returns the log fields attached to ctx with WithLogFields.
+func logFieldsFromContext(ctx context.Context) []interface{} {
+	fields, _ := ctx.Value(logFieldsContextKey{}).([]interface{})
+	return fields
+}
+
+// logFields logs msg with the given fields. If the logger of the client does not implement
+// StructuredLogger, the fields are appended to the message as key=value pairs.
+func logFields(ctx context.Context, logger LeveledLoggerInterface, level Level, msg string, keysAndValues ...interface{}) {
+	if structured, ok := logger.(StructuredLogger); ok {
+		structured.Log(ctx, level, msg, keysAndValues...)
+		return
+	}
+
+	line := formatFields(msg, keysAndValues)
+
+	switch level {
+	case LevelError:
+		logger.Errorf("%s", line)
+	case LevelWarn:
+		logger.Warnf("%s", line)
+	case LevelInfo:
+		logger.Infof("%s", line)
+	case LevelDebug:
+		logger.Debugf("%s", line)
+	}
+}
+
+// formatFields appends the key/value pairs to msg in the key=value format.
+func formatFields(msg string, keysAndValues []interface{}) string {
+	var b strings.Builder
+	b.WriteString(msg)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "MISSING"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+
+		s := fmt.Sprint(value)
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			s = strconv.Quote(s)
+		}
+
+		fmt.Fprintf(&b, " %v=%s", keysAndValues[i], s)
+	}
+
+	return b.String()
+}
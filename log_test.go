@@ -2,8 +2,12 @@ package mobilepay
 
 import (
 	"bytes"
-	"github.com/stretchr/testify/assert"
+	"context"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
 )
 
 //
@@ -105,6 +109,55 @@ func TestLeveledLoggerErrorf(t *testing.T) {
 	}
 }
 
+//
+// Structured logging
+//
+
+func TestStructuredLoggerFunc_Request_Fields(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/payments/186d2b31-ff25-4414-9fd1-bfe9807fa8b7").
+		Reply(200).
+		SetHeader("CorrelationId", "d503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a").
+		JSON(map[string]string{"paymentId": "186d2b31-ff25-4414-9fd1-bfe9807fa8b7"})
+
+	var fields map[string]interface{}
+	var msg string
+	logger := NewStructuredLeveledLogger(StructuredLoggerFunc(func(ctx context.Context, level Level, m string, keysAndValues ...interface{}) {
+		msg = m
+		fields = make(map[string]interface{})
+		for i := 0; i+1 < len(keysAndValues); i += 2 {
+			fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+		}
+	}))
+
+	client := New("test", "test", &Config{URL: TestBaseUrl, Logger: logger})
+
+	_, err := client.Payment.Find(context.TODO(), "186d2b31-ff25-4414-9fd1-bfe9807fa8b7")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "MobilePay request completed", msg)
+	assert.Equal(t, "GET", fields["method"])
+	assert.Equal(t, "/v1/payments/186d2b31-ff25-4414-9fd1-bfe9807fa8b7", fields["path"])
+	assert.Equal(t, 200, fields["status"])
+	assert.Equal(t, 1, fields["attempt"])
+	assert.IsType(t, time.Duration(0), fields["latency"])
+	assert.Equal(t, "d503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a", fields["correlation_id"])
+	assert.Equal(t, "186d2b31-ff25-4414-9fd1-bfe9807fa8b7", fields["payment_id"])
+}
+
+func TestLeveledLogger_Fields_Fallback(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	logger := &LeveledLogger{Level: LevelInfo, stdoutOverride: &stdout, stderrOverride: &stderr}
+
+	logFields(context.TODO(), logger, LevelInfo, "MobilePay request completed", "method", "GET", "status", 200, "error", "connection refused")
+	assert.Equal(t, "[INFO] MobilePay request completed method=GET status=200 error=\"connection refused\"\n", stdout.String())
+
+	logFields(context.TODO(), logger, LevelWarn, "odd", "key")
+	assert.Equal(t, "[WARN] odd key=MISSING\n", stderr.String())
+}
+
 //
 // Private functions
 //
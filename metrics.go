@@ -0,0 +1,64 @@
+package mobilepay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Metrics records metrics for every call to the MobilePay API made through Client.Do.
+//
+// PrometheusMetrics is an implementation that can be scraped by Prometheus.
+type Metrics interface {
+	// ObserveRequest is called once a call has completed, after any retries.
+	ObserveRequest(ctx context.Context, metric RequestMetric)
+}
+
+// RequestMetric describes the outcome of a single call to the MobilePay API.
+type RequestMetric struct {
+	// Operation is the service method that made the call. It is empty for requests sent
+	// directly through Client.Do.
+	Operation Operation
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// StatusCode is the HTTP status code of the response, or 0 when no response was received.
+	StatusCode int
+
+	// ErrorCode is the MobilePay error code of a failed call, if any.
+	ErrorCode ErrorCode
+
+	// Err is the error returned by the call.
+	Err error
+
+	// Duration is the time spent on the call including all retries.
+	Duration time.Duration
+}
+
+// Failed reports whether the call returned an error.
+func (m RequestMetric) Failed() bool {
+	return m.Err != nil
+}
+
+func newRequestMetric(op Operation, req *http.Request, resp *http.Response, err error, duration time.Duration) RequestMetric {
+	metric := RequestMetric{
+		Operation: op,
+		Method:    req.Method,
+		Err:       err,
+		Duration:  duration,
+	}
+
+	if resp != nil {
+		metric.StatusCode = resp.StatusCode
+	}
+
+	var errorResponse *ErrorResponse
+	if errors.As(err, &errorResponse) {
+		metric.StatusCode = errorResponse.StatusCode
+		metric.ErrorCode = errorResponse.Code()
+	}
+
+	return metric
+}
@@ -0,0 +1,120 @@
+package mobilepay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+type recordingMetrics struct {
+	metrics []RequestMetric
+}
+
+func (m *recordingMetrics) ObserveRequest(ctx context.Context, metric RequestMetric) {
+	m.metrics = append(m.metrics, metric)
+}
+
+func TestMetrics_Observe_Request(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture").
+		Reply(409).
+		File("testdata/capture_payment_409_amount_too_large.json")
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	gock.New(TestBaseUrl).
+		Delete("/v1/webhooks/e4a2e195-74f6-42e1-a172-83291c9d2a41").
+		ReplyError(errors.New("connection reset by peer"))
+
+	metrics := &recordingMetrics{}
+	client, err := NewWithOptions("test", "test", WithBaseURL(TestBaseUrl), WithMetrics(metrics))
+	assert.Nil(t, err)
+
+	err = client.Payment.Capture(context.TODO(), "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", 100000)
+	assert.Error(t, err)
+
+	_, err = client.Webhook.Get(context.TODO())
+	assert.Nil(t, err)
+
+	err = client.Webhook.Delete(context.TODO(), "e4a2e195-74f6-42e1-a172-83291c9d2a41")
+	assert.Error(t, err)
+
+	assert.Len(t, metrics.metrics, 3)
+
+	assert.Equal(t, "Payment.Capture", metrics.metrics[0].Operation.Name)
+	assert.Equal(t, http.MethodPost, metrics.metrics[0].Method)
+	assert.Equal(t, 409, metrics.metrics[0].StatusCode)
+	assert.Equal(t, ErrorCodeAmountTooLarge, metrics.metrics[0].ErrorCode)
+	assert.True(t, metrics.metrics[0].Failed())
+
+	assert.Equal(t, "Webhook.Get", metrics.metrics[1].Operation.Name)
+	assert.Equal(t, 200, metrics.metrics[1].StatusCode)
+	assert.False(t, metrics.metrics[1].Failed())
+	assert.True(t, metrics.metrics[1].Duration > 0)
+
+	assert.Equal(t, "Webhook.Delete", metrics.metrics[2].Operation.Name)
+	assert.Equal(t, 0, metrics.metrics[2].StatusCode)
+	assert.True(t, metrics.metrics[2].Failed())
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := NewPrometheusMetrics(0.1, 1)
+
+	capture := Operation{Name: "Payment.Capture", Route: "v1/payments/{id}/capture"}
+	metrics.ObserveRequest(context.TODO(), RequestMetric{Operation: capture, Method: http.MethodPost, StatusCode: 204, Duration: 50 * time.Millisecond})
+	metrics.ObserveRequest(context.TODO(), RequestMetric{Operation: capture, Method: http.MethodPost, StatusCode: 409, ErrorCode: ErrorCodeAmountTooLarge, Err: errors.New("conflict"), Duration: 500 * time.Millisecond})
+	metrics.ObserveRequest(context.TODO(), RequestMetric{Method: http.MethodGet, Err: errors.New("timeout"), Duration: 2 * time.Second})
+
+	expected := `# HELP mobilepay_requests_total Total number of calls to the MobilePay API.
+# TYPE mobilepay_requests_total counter
+mobilepay_requests_total{operation="Payment.Capture",method="POST"} 2
+mobilepay_requests_total{operation="unknown",method="GET"} 1
+# HELP mobilepay_request_errors_total Total number of failed calls to the MobilePay API.
+# TYPE mobilepay_request_errors_total counter
+mobilepay_request_errors_total{operation="Payment.Capture",status="409",code="amount_too_large"} 1
+mobilepay_request_errors_total{operation="unknown",status="",code=""} 1
+# HELP mobilepay_request_duration_seconds Latency of calls to the MobilePay API including retries.
+# TYPE mobilepay_request_duration_seconds histogram
+mobilepay_request_duration_seconds_bucket{operation="Payment.Capture",le="0.1"} 1
+mobilepay_request_duration_seconds_bucket{operation="Payment.Capture",le="1"} 2
+mobilepay_request_duration_seconds_bucket{operation="Payment.Capture",le="+Inf"} 2
+mobilepay_request_duration_seconds_sum{operation="Payment.Capture"} 0.55
+mobilepay_request_duration_seconds_count{operation="Payment.Capture"} 2
+mobilepay_request_duration_seconds_bucket{operation="unknown",le="0.1"} 0
+mobilepay_request_duration_seconds_bucket{operation="unknown",le="1"} 0
+mobilepay_request_duration_seconds_bucket{operation="unknown",le="+Inf"} 1
+mobilepay_request_duration_seconds_sum{operation="unknown"} 2
+mobilepay_request_duration_seconds_count{operation="unknown"} 1
+`
+
+	var b strings.Builder
+	_, err := metrics.WriteTo(&b)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, b.String())
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, expected, rec.Body.String())
+}
+
+func TestQuoteLabel(t *testing.T) {
+	assert.Equal(t, `"a\"b\\c\nd"`, quoteLabel("a\"b\\c\nd"))
+}
+
+func TestWithMetrics_Nil(t *testing.T) {
+	_, err := NewWithOptions("test", "test", WithMetrics(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package mobilepay
+
+import (
+	"context"
+	"net/http"
+)
+
+// Doer sends a request to the MobilePay API for the given operation.
+//
+// A non-2xx response is returned together with the decoded error, usually an *ErrorResponse.
+// The body of a successful response is decoded by Client.Do once the chain has returned.
+type Doer interface {
+	Do(ctx context.Context, op Operation, req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as a Doer.
+type DoerFunc func(ctx context.Context, op Operation, req *http.Request) (*http.Response, error)
+
+// Do calls f(ctx, op, req).
+func (f DoerFunc) Do(ctx context.Context, op Operation, req *http.Request) (*http.Response, error) {
+	return f(ctx, op, req)
+}
+
+// Middleware wraps the Doer sending requests to MobilePay, e.g. to refresh credentials,
+// sign requests, cache responses or inject faults.
+//
+// A middleware calling next more than once must close the body of every response it discards.
+// The request body is rewound before every call to the innermost Doer.
+type Middleware func(next Doer) Doer
+
+// Use appends middleware to the chain wrapping every request. The first middleware added is
+// the outermost one. Use must not be called concurrently with requests.
+func (c *Client) Use(middleware ...Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// doer returns the chain of middleware around the Doer sending requests.
+func (c *Client) doer() Doer {
+	var doer Doer = DoerFunc(c.roundTrip)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		doer = c.middleware[i](doer)
+	}
+
+	return doer
+}
+
+// roundTrip authenticates and sends req. When the credentials of a CredentialsProvider are
+// rejected, they are refreshed and the request is sent once more.
+func (c *Client) roundTrip(ctx context.Context, op Operation, req *http.Request) (*http.Response, error) {
+	if c.credentialsProvider == nil {
+		return c.doAndCheck(ctx, req)
+	}
+
+	credentials, err := c.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	setCredentials(req, credentials)
+
+	resp, err := c.doAndCheck(ctx, req)
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// MobilePay rejected the credentials, they may have been rotated.
+	refreshed, ok := c.refreshCredentials(ctx, credentials)
+	if !ok {
+		return resp, err
+	}
+	_ = resp.Body.Close()
+	setCredentials(req, refreshed)
+
+	return c.doAndCheck(ctx, req)
+}
+
+// doAndCheck sends req, retrying it according to the retry policy, and decodes error responses.
+func (c *Client) doAndCheck(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if err := rewindBody(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doWithRetries(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.onRequestCompleted != nil {
+		c.onRequestCompleted(req, resp)
+	}
+
+	if err := CheckResponse(resp); err != nil {
+		if errorResponse, ok := err.(*ErrorResponse); ok {
+			errorResponse.redactor = c.redactor
+		}
+
+		return resp, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,139 @@
+package mobilepay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestMiddleware_Order_Operation_And_Decoded_Error(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture").
+		Reply(409).
+		File("testdata/capture_payment_409_amount_too_large.json")
+
+	var calls []string
+	var seenErr error
+	record := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(ctx context.Context, op Operation, req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" "+op.Name+" "+req.URL.Path)
+				resp, err := next.Do(ctx, op, req)
+				seenErr = err
+				return resp, err
+			})
+		}
+	}
+
+	client, err := NewWithOptions("test", "test", WithBaseURL(TestBaseUrl), WithMiddleware(record("first")))
+	assert.Nil(t, err)
+	client.Use(record("second"))
+
+	err = client.Payment.Capture(context.TODO(), "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", 100000)
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"first Payment.Capture /v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture",
+		"second Payment.Capture /v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture",
+	}, calls)
+
+	var errorResponse *ErrorResponse
+	assert.True(t, errors.As(seenErr, &errorResponse))
+	assert.Equal(t, ErrorCodeAmountTooLarge, errorResponse.Code())
+	assert.True(t, gock.IsDone())
+}
+
+func TestMiddleware_Short_Circuit(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	cache := func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, op Operation, req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"paymentId":"186d2b31-ff25-4414-9fd1-bfe9807fa8b7"}`)),
+				Request:    req,
+			}, nil
+		})
+	}
+
+	client, err := NewWithOptions("test", "test", WithBaseURL(TestBaseUrl), WithMiddleware(cache))
+	assert.Nil(t, err)
+
+	payment, err := client.Payment.Find(context.TODO(), "186d2b31-ff25-4414-9fd1-bfe9807fa8b7")
+	assert.Nil(t, err)
+	assert.Equal(t, "186d2b31-ff25-4414-9fd1-bfe9807fa8b7", payment.PaymentId)
+}
+
+func TestMiddleware_Fault_Injection(t *testing.T) {
+	errInjected := errors.New("injected fault")
+	fault := func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, op Operation, req *http.Request) (*http.Response, error) {
+			return nil, errInjected
+		})
+	}
+
+	client, err := NewWithOptions("test", "test", WithBaseURL(TestBaseUrl), WithMiddleware(fault))
+	assert.Nil(t, err)
+
+	_, err = client.Webhook.Get(context.TODO())
+	assert.ErrorIs(t, err, errInjected)
+}
+
+func TestMiddleware_Resend_Rewinds_Body(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	params := &RefundParams{
+		Amount:         100,
+		IdempotencyKey: "7576910d-9789-4fef-a72e-877d89afec94",
+		PaymentId:      "211444eb-1c4e-4194-a58f-905d97877cc5",
+		Reference:      "test",
+		Description:    "this is a test payment",
+	}
+
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		JSON(params).
+		Reply(401)
+
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		MatchHeader("Authorization", "Bearer refreshed").
+		JSON(params).
+		Reply(200).
+		JSON(map[string]interface{}{"paymentId": params.PaymentId, "amount": 100})
+
+	refresh := func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, op Operation, req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(ctx, op, req)
+			if !IsAuthError(err) {
+				return resp, err
+			}
+			_ = resp.Body.Close()
+
+			req.Header.Set("Authorization", "Bearer refreshed")
+			return next.Do(ctx, op, req)
+		})
+	}
+
+	client, err := NewWithOptions("test", "test", WithBaseURL(TestBaseUrl), WithMiddleware(refresh))
+	assert.Nil(t, err)
+
+	refund, err := client.Payment.Refund.Create(context.TODO(), params)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, refund.Amount)
+	assert.True(t, gock.IsDone())
+}
+
+func TestWithMiddleware_Nil(t *testing.T) {
+	_, err := NewWithOptions("test", "test", WithMiddleware(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+// Package mobilepayotel traces calls to the MobilePay API with OpenTelemetry.
+//
+//	mp, err := mobilepay.NewWithOptions(clientId, apiKey,
+//		mobilepay.WithTracer(mobilepayotel.NewTracer()),
+//	)
+//
+// Every API call gets a client span named after the operation, e.g. "Payment.Capture", and
+// the W3C trace context is propagated to MobilePay.
+package mobilepayotel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/steffen25/mobilepay-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer used to create spans.
+const instrumentationName = "github.com/steffen25/mobilepay-go/mobilepayotel"
+
+// Span attribute keys.
+const (
+	HTTPMethodKey    = attribute.Key("http.method")
+	HTTPRouteKey     = attribute.Key("http.route")
+	HTTPStatusKey    = attribute.Key("http.status_code")
+	NetPeerNameKey   = attribute.Key("net.peer.name")
+	OperationKey     = attribute.Key("mobilepay.operation")
+	ErrorCodeKey     = attribute.Key("mobilepay.error_code")
+	CorrelationIDKey = attribute.Key("mobilepay.correlation_id")
+)
+
+// correlationIdHeader is the response header carrying the MobilePay correlation id.
+const correlationIdHeader = "CorrelationId"
+
+// Option configures a Tracer.
+type Option func(*Tracer)
+
+// WithTracerProvider sets the provider used to create spans. The global provider is used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(t *Tracer) {
+		if provider != nil {
+			t.provider = provider
+		}
+	}
+}
+
+// WithPropagator sets the propagator injecting the trace context into requests.
+// The W3C trace context propagator is used by default.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(t *Tracer) {
+		if propagator != nil {
+			t.propagator = propagator
+		}
+	}
+}
+
+// Tracer is a mobilepay.Tracer backed by OpenTelemetry.
+type Tracer struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+	tracer     trace.Tracer
+}
+
+var _ mobilepay.Tracer = (*Tracer)(nil)
+
+// NewTracer returns a tracer to be passed to mobilepay.WithTracer.
+func NewTracer(opts ...Option) *Tracer {
+	t := &Tracer{
+		provider:   otel.GetTracerProvider(),
+		propagator: propagation.TraceContext{},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	t.tracer = t.provider.Tracer(instrumentationName, trace.WithInstrumentationVersion(mobilepay.LibraryVersion))
+
+	return t
+}
+
+// Start starts a client span for op and injects its trace context into the request headers.
+func (t *Tracer) Start(ctx context.Context, op mobilepay.Operation, req *http.Request) (context.Context, mobilepay.Span) {
+	name := op.Name
+	if name == "" {
+		name = "MobilePay " + req.Method
+	}
+
+	attrs := []attribute.KeyValue{
+		HTTPMethodKey.String(req.Method),
+		NetPeerNameKey.String(req.URL.Hostname()),
+	}
+	if op.Name != "" {
+		attrs = append(attrs, OperationKey.String(op.Name))
+	}
+	if op.Route != "" {
+		attrs = append(attrs, HTTPRouteKey.String(op.Route))
+	}
+
+	ctx, otelSpan := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return ctx, &span{span: otelSpan}
+}
+
+// span ends an OpenTelemetry span with the outcome of the call.
+type span struct {
+	span trace.Span
+}
+
+func (s *span) End(resp *http.Response, err error) {
+	defer s.span.End()
+
+	if resp != nil {
+		s.span.SetAttributes(HTTPStatusKey.Int(resp.StatusCode))
+		if correlationId := resp.Header.Get(correlationIdHeader); correlationId != "" {
+			s.span.SetAttributes(CorrelationIDKey.String(correlationId))
+		}
+	}
+
+	var errorResponse *mobilepay.ErrorResponse
+	if errors.As(err, &errorResponse) {
+		if code := errorResponse.Code(); code != "" {
+			s.span.SetAttributes(ErrorCodeKey.String(string(code)))
+		}
+		if correlationId := errorResponse.CorrelationID(); correlationId != "" {
+			s.span.SetAttributes(CorrelationIDKey.String(correlationId))
+		}
+	}
+
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+}
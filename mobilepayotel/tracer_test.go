@@ -0,0 +1,118 @@
+package mobilepayotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steffen25/mobilepay-go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/h2non/gock.v1"
+)
+
+const paymentId = "206d2b31-ff25-4414-9fd1-bfe9807fa8b7"
+
+func newTestClient(t *testing.T) (*mobilepay.Client, *tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	client, err := mobilepay.NewWithOptions("test", "test",
+		mobilepay.WithBaseURL(mobilepay.TestBaseUrl),
+		mobilepay.WithTracer(NewTracer(WithTracerProvider(provider))),
+	)
+	assert.NoError(t, err)
+
+	return client, exporter, provider
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func TestTracer_Capture(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(mobilepay.TestBaseUrl).
+		Post("/v1/payments/"+paymentId+"/capture").
+		MatchHeader("traceparent", "^00-[0-9a-f]{32}-[0-9a-f]{16}-01$").
+		Reply(204).
+		SetHeader("CorrelationId", "a503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a")
+
+	client, exporter, _ := newTestClient(t)
+
+	err := client.Payment.Capture(context.TODO(), paymentId, 100)
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "Payment.Capture", span.Name)
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+	assert.Equal(t, codes.Unset, span.Status.Code)
+
+	attrs := attributes(span)
+	assert.Equal(t, "POST", attrs[HTTPMethodKey].AsString())
+	assert.Equal(t, "v1/payments/{id}/capture", attrs[HTTPRouteKey].AsString())
+	assert.Equal(t, int64(204), attrs[HTTPStatusKey].AsInt64())
+	assert.Equal(t, "a503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a", attrs[CorrelationIDKey].AsString())
+	assert.Equal(t, "api.sandbox.mobilepay.dk", attrs[NetPeerNameKey].AsString())
+}
+
+func TestTracer_Conflict(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(mobilepay.TestBaseUrl).
+		Post("/v1/payments/" + paymentId + "/capture").
+		Reply(409).
+		File("../testdata/capture_payment_409_amount_too_large.json")
+
+	client, exporter, _ := newTestClient(t)
+
+	err := client.Payment.Capture(context.TODO(), paymentId, 100000)
+	assert.Error(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Len(t, span.Events, 1, "the error should be recorded")
+
+	attrs := attributes(span)
+	assert.Equal(t, int64(409), attrs[HTTPStatusKey].AsInt64())
+	assert.Equal(t, "amount_too_large", attrs[ErrorCodeKey].AsString())
+	assert.Equal(t, "d503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a", attrs[CorrelationIDKey].AsString())
+}
+
+func TestTracer_Parent_Span(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(mobilepay.TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	client, exporter, provider := newTestClient(t)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.Webhook.Get(ctx)
+	assert.Nil(t, err)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "Webhook.Get", spans[0].Name)
+	assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext.TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+}
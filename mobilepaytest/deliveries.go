@@ -0,0 +1,259 @@
+package mobilepaytest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/steffen25/mobilepay-go"
+)
+
+// signatureHeader is the header MobilePay uses to sign webhook requests.
+const signatureHeader = "x-mobilepay-signature"
+
+// Delivery is a webhook notification sent, or held back, by the fake server.
+type Delivery struct {
+	Notification mobilepay.WebhookNotification
+	WebhookId    string
+	Url          string
+	Body         []byte
+	Signature    string
+
+	// StatusCode is the status code returned by the webhook, or zero if the notification
+	// has not been delivered or the request failed.
+	StatusCode int
+	// Err is the error that occurred while delivering the notification, if any.
+	Err error
+}
+
+// Sign returns the signature MobilePay sends in the x-mobilepay-signature header:
+// the base64 encoded HMAC-SHA1 of the webhook url followed by the body, keyed by the
+// signature key of the webhook.
+func Sign(webhookUrl, signatureKey string, body []byte) string {
+	mac := hmac.New(sha1.New, []byte(signatureKey))
+	mac.Write([]byte(webhookUrl))
+	mac.Write(body)
+
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// notify builds a delivery for every webhook subscribed to event. It must be called with
+// s.mu held, the returned deliveries must be sent with s.deliver after s.mu is released.
+func (s *Server) notify(event mobilepay.WebhookEvent, dataType mobilepay.WebhookDataType, id string) []*Delivery {
+	notification, body := s.newNotification(event, dataType, id)
+
+	var deliveries []*Delivery
+	for _, webhookId := range s.webhookOrder {
+		webhook := s.webhooks[webhookId]
+		if !subscribed(webhook, event) {
+			continue
+		}
+
+		deliveries = append(deliveries, newDelivery(webhook, notification, body))
+	}
+
+	return s.hold(deliveries)
+}
+
+// notifyWebhook builds a delivery of event to the given webhook, whether it is subscribed to
+// event or not. The same locking rules as for notify apply.
+func (s *Server) notifyWebhook(webhook *mobilepay.Webhook, event mobilepay.WebhookEvent, dataType mobilepay.WebhookDataType, id string) []*Delivery {
+	notification, body := s.newNotification(event, dataType, id)
+
+	return s.hold([]*Delivery{newDelivery(webhook, notification, body)})
+}
+
+func (s *Server) newNotification(event mobilepay.WebhookEvent, dataType mobilepay.WebhookDataType, id string) (mobilepay.WebhookNotification, []byte) {
+	notification := mobilepay.WebhookNotification{
+		NotificationId: newID(),
+		EventType:      event,
+		EventDate:      s.now().UTC().Truncate(time.Second),
+		Data:           mobilepay.WebhookNotificationData{Type: dataType, Id: id},
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		panic(err)
+	}
+
+	return notification, body
+}
+
+func newDelivery(webhook *mobilepay.Webhook, notification mobilepay.WebhookNotification, body []byte) *Delivery {
+	return &Delivery{
+		Notification: notification,
+		WebhookId:    webhook.WebhookId,
+		Url:          webhook.Url,
+		Body:         body,
+		Signature:    Sign(webhook.Url, webhook.SignatureKey, body),
+	}
+}
+
+// hold keeps the deliveries back while deliveries are held and returns the ones to send now.
+func (s *Server) hold(deliveries []*Delivery) []*Delivery {
+	if s.holding {
+		s.held = append(s.held, deliveries...)
+		return nil
+	}
+
+	return deliveries
+}
+
+func subscribed(webhook *mobilepay.Webhook, event mobilepay.WebhookEvent) bool {
+	for _, e := range webhook.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deliver posts the notifications to their webhooks. It must be called without s.mu held.
+func (s *Server) deliver(deliveries []*Delivery) {
+	for _, d := range deliveries {
+		s.send(d)
+	}
+}
+
+func (s *Server) send(d *Delivery) {
+	d.StatusCode, d.Err = 0, nil
+
+	req, err := http.NewRequest(http.MethodPost, d.Url, bytes.NewReader(d.Body))
+	if err != nil {
+		d.Err = err
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(signatureHeader, d.Signature)
+
+		resp, err := s.webhookClient().Do(req)
+		if err != nil {
+			d.Err = err
+		} else {
+			d.StatusCode = resp.StatusCode
+			resp.Body.Close()
+		}
+	}
+
+	s.mu.Lock()
+	s.deliveries = append(s.deliveries, *d)
+	s.mu.Unlock()
+}
+
+func (s *Server) webhookClient() *http.Client {
+	if s.WebhookHTTPClient != nil {
+		return s.WebhookHTTPClient
+	}
+
+	return http.DefaultClient
+}
+
+// Deliveries returns every delivery attempt made by the server in the order they were made.
+// Redelivered notifications appear once per attempt.
+func (s *Server) Deliveries() []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Delivery(nil), s.deliveries...)
+}
+
+// Redeliver sends a notification that was delivered before again, simulating MobilePay
+// delivering the same notification more than once.
+func (s *Server) Redeliver(notificationId string) error {
+	s.mu.Lock()
+	var deliveries []*Delivery
+	for _, d := range s.deliveries {
+		if d.Notification.NotificationId == notificationId {
+			d := d
+			deliveries = append(deliveries, &d)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(deliveries) == 0 {
+		return fmt.Errorf("mobilepaytest: notification %s was never delivered", notificationId)
+	}
+
+	// only send the notification once to every webhook, even if it was redelivered before.
+	seen := make(map[string]bool)
+	for _, d := range deliveries {
+		if !seen[d.WebhookId] {
+			seen[d.WebhookId] = true
+			s.send(d)
+		}
+	}
+
+	return nil
+}
+
+// HoldDeliveries makes the server queue notifications instead of sending them, until they are
+// sent with DeliverHeld or ResumeDeliveries. Use it to deliver notifications out of order.
+func (s *Server) HoldDeliveries() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.holding = true
+}
+
+// Held returns the notifications that are queued and not yet sent.
+func (s *Server) Held() []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	held := make([]Delivery, len(s.held))
+	for i, d := range s.held {
+		held[i] = *d
+	}
+
+	return held
+}
+
+// DeliverHeld sends the held notification with the given id now.
+func (s *Server) DeliverHeld(notificationId string) error {
+	s.mu.Lock()
+	var deliveries, remaining []*Delivery
+	for _, d := range s.held {
+		if d.Notification.NotificationId == notificationId {
+			deliveries = append(deliveries, d)
+		} else {
+			remaining = append(remaining, d)
+		}
+	}
+	s.held = remaining
+	s.mu.Unlock()
+
+	if len(deliveries) == 0 {
+		return fmt.Errorf("mobilepaytest: notification %s is not held", notificationId)
+	}
+
+	s.deliver(deliveries)
+
+	return nil
+}
+
+// ResumeDeliveries sends all held notifications in the order they were created and
+// makes the server send new notifications immediately again.
+func (s *Server) ResumeDeliveries() {
+	s.mu.Lock()
+	deliveries := s.held
+	s.held = nil
+	s.holding = false
+	s.mu.Unlock()
+
+	s.deliver(deliveries)
+}
+
+// ActivatePaymentPoint simulates MobilePay activating a payment point and notifies the
+// webhooks subscribed to paymentpoint.activated.
+func (s *Server) ActivatePaymentPoint(paymentPointId string) {
+	s.mu.Lock()
+	deliveries := s.notify(mobilepay.PaymentPointActivated.Name(), mobilepay.WebhookDataTypePaymentPoint, paymentPointId)
+	s.mu.Unlock()
+
+	s.deliver(deliveries)
+}
@@ -0,0 +1,245 @@
+package mobilepaytest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steffen25/mobilepay-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type receiver struct {
+	mu            sync.Mutex
+	notifications []mobilepay.WebhookNotification
+}
+
+func (r *receiver) record(ctx context.Context, n *mobilepay.WebhookNotification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifications = append(r.notifications, *n)
+
+	return nil
+}
+
+func (r *receiver) received() []mobilepay.WebhookNotification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]mobilepay.WebhookNotification(nil), r.notifications...)
+}
+
+// newReceiver starts a webhook endpoint verifying notifications with mobilepay.WebhookHandler
+// and registers it for the given events.
+func newReceiver(t *testing.T, srv *Server, events ...mobilepay.WebhookEvent) (*receiver, func()) {
+	r := &receiver{}
+
+	var handler http.Handler
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		handler.ServeHTTP(w, req)
+	}))
+
+	webhook, err := srv.Client().Webhook.Create(context.TODO(), &mobilepay.WebhookCreateParams{
+		Events: events,
+		Url:    endpoint.URL + "/webhooks",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhookHandler := mobilepay.NewWebhookHandler(webhook.Url, webhook.SignatureKey)
+	for _, event := range events {
+		webhookHandler.On(event, r.record)
+	}
+	handler = webhookHandler
+
+	return r, endpoint.Close
+}
+
+func createPayment(t *testing.T, srv *Server, idempotencyKey string) string {
+	created, err := srv.Client().Payment.Create(context.TODO(), newPaymentParams(idempotencyKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return created.PaymentId
+}
+
+func TestServer_Delivers_Signed_Notifications(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	r, closeReceiver := newReceiver(t, srv, mobilepay.PaymentReserved.Name(), mobilepay.PaymentExpired.Name(), mobilepay.PaymentPointActivated.Name())
+	defer closeReceiver()
+
+	paymentId := createPayment(t, srv, "7347ba06-95c5-4181-82e5-7c7a23609a0e")
+
+	assert.Nil(t, srv.Reserve(paymentId))
+	assert.Nil(t, srv.Expire(paymentId))
+	srv.ActivatePaymentPoint("1f8ed17f-f310-4f40-a7a4-df78185efbdd")
+
+	received := r.received()
+	if assert.Len(t, received, 3) {
+		assert.Equal(t, mobilepay.PaymentReserved.Name(), received[0].EventType)
+		assert.Equal(t, paymentId, received[0].Data.Id)
+		assert.Equal(t, mobilepay.WebhookDataTypePayment, received[0].Data.Type)
+		assert.Equal(t, mobilepay.PaymentExpired.Name(), received[1].EventType)
+		assert.Equal(t, mobilepay.PaymentPointActivated.Name(), received[2].EventType)
+		assert.Equal(t, "1f8ed17f-f310-4f40-a7a4-df78185efbdd", received[2].Data.Id)
+	}
+
+	for _, d := range srv.Deliveries() {
+		assert.Nil(t, d.Err)
+		assert.Equal(t, http.StatusOK, d.StatusCode)
+	}
+}
+
+func TestServer_Delivers_Only_Subscribed_Events(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	reserved, closeReserved := newReceiver(t, srv, mobilepay.PaymentReserved.Name())
+	defer closeReserved()
+
+	expired, closeExpired := newReceiver(t, srv, mobilepay.PaymentExpired.Name())
+	defer closeExpired()
+
+	paymentId := createPayment(t, srv, "7347ba06-95c5-4181-82e5-7c7a23609a0e")
+	assert.Nil(t, srv.Reserve(paymentId))
+
+	assert.Len(t, reserved.received(), 1)
+	assert.Len(t, expired.received(), 0)
+	assert.Len(t, srv.Deliveries(), 1)
+}
+
+func TestServer_Redeliver(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	r, closeReceiver := newReceiver(t, srv, mobilepay.PaymentReserved.Name())
+	defer closeReceiver()
+
+	paymentId := createPayment(t, srv, "7347ba06-95c5-4181-82e5-7c7a23609a0e")
+	assert.Nil(t, srv.Reserve(paymentId))
+
+	notificationId := srv.Deliveries()[0].Notification.NotificationId
+	assert.Nil(t, srv.Redeliver(notificationId))
+	assert.Error(t, srv.Redeliver("unknown"))
+
+	received := r.received()
+	if assert.Len(t, received, 2) {
+		assert.Equal(t, received[0], received[1])
+	}
+	assert.Len(t, srv.Deliveries(), 2)
+}
+
+func TestServer_Out_Of_Order_Deliveries(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	r, closeReceiver := newReceiver(t, srv, mobilepay.PaymentReserved.Name(), mobilepay.PaymentExpired.Name())
+	defer closeReceiver()
+
+	srv.HoldDeliveries()
+
+	first := createPayment(t, srv, "7347ba06-95c5-4181-82e5-7c7a23609a0e")
+	second := createPayment(t, srv, "8347ba06-95c5-4181-82e5-7c7a23609a0e")
+	assert.Nil(t, srv.Reserve(first))
+	assert.Nil(t, srv.Expire(first))
+	assert.Nil(t, srv.Reserve(second))
+
+	held := srv.Held()
+	assert.Len(t, held, 3)
+	assert.Len(t, r.received(), 0)
+
+	// the expiry of the first payment arrives before its reservation.
+	assert.Nil(t, srv.DeliverHeld(held[1].Notification.NotificationId))
+	assert.Error(t, srv.DeliverHeld(held[1].Notification.NotificationId))
+	srv.ResumeDeliveries()
+
+	received := r.received()
+	if assert.Len(t, received, 3) {
+		assert.Equal(t, mobilepay.PaymentExpired.Name(), received[0].EventType)
+		assert.Equal(t, mobilepay.PaymentReserved.Name(), received[1].EventType)
+		assert.Equal(t, first, received[1].Data.Id)
+		assert.Equal(t, second, received[2].Data.Id)
+	}
+
+	// new notifications are sent immediately once deliveries are resumed.
+	third := createPayment(t, srv, "9347ba06-95c5-4181-82e5-7c7a23609a0e")
+	assert.Nil(t, srv.Reserve(third))
+	assert.Len(t, r.received(), 4)
+	assert.Len(t, srv.Held(), 0)
+}
+
+func TestServer_Delivery_Rejected(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer endpoint.Close()
+
+	_, err := srv.Client().Webhook.Create(context.TODO(), &mobilepay.WebhookCreateParams{
+		Events: []mobilepay.WebhookEvent{mobilepay.PaymentReserved.Name()},
+		Url:    endpoint.URL,
+	})
+	assert.Nil(t, err)
+
+	paymentId := createPayment(t, srv, "7347ba06-95c5-4181-82e5-7c7a23609a0e")
+	assert.Nil(t, srv.Reserve(paymentId))
+
+	deliveries := srv.Deliveries()
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, http.StatusInternalServerError, deliveries[0].StatusCode)
+	}
+}
+
+func TestServer_Verify_Webhook(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mp := srv.Client()
+
+	webhook, err := mp.Webhook.Create(context.TODO(), &mobilepay.WebhookCreateParams{
+		Events: []mobilepay.WebhookEvent{mobilepay.PaymentReserved.Name()},
+		Url:    "http://" + listener.Addr().String() + "/webhooks",
+	})
+	assert.Nil(t, err)
+
+	err = mp.Webhook.Verify(context.TODO(), webhook, mobilepay.WithVerifyListener(listener))
+	assert.Nil(t, err)
+
+	// the delivery is recorded once the fake has read the response of the webhook.
+	assert.Eventually(t, func() bool { return len(srv.Deliveries()) == 1 }, time.Second, 10*time.Millisecond)
+
+	deliveries := srv.Deliveries()
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+		assert.Equal(t, mobilepay.TestNotification.Name(), deliveries[0].Notification.EventType)
+		assert.Equal(t, mobilepay.WebhookDataTypeTest, deliveries[0].Notification.Data.Type)
+		assert.Equal(t, webhook.WebhookId, deliveries[0].WebhookId)
+	}
+
+	err = mp.Webhook.PublishTestNotification(context.TODO(), "e4a2e195-74f6-42e1-a172-83291c9d2a41")
+	assert.Equal(t, CodeWebhookNotFound, conflictCode(t, err))
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"notificationId":"4352f1ae-59c3-430c-a402-d74641dd8555","eventType":"test.notification","eventDate":"2022-02-20T16:35:28Z","data":{"type":"test","id":"57ff4ddf-575f-4c4a-99c8-b190a1e1f316"}}`)
+
+	signature := Sign("https://webhook.site/080a55d2-ff87-4494-a05c-e0e3beb78134", "4aa30d41-4368-47a0-b4ef-6a83dc8be5d6", body)
+
+	assert.Equal(t, "HIcf0Ivp0HwjB2qVIwU1vIdf/60=", signature)
+}
@@ -0,0 +1,262 @@
+package mobilepaytest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/steffen25/mobilepay-go"
+)
+
+func (s *Server) servePayments(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		s.listPayments(w, r)
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		s.createPayment(w, r)
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		s.getPayment(w, segments[0])
+	case len(segments) == 2 && segments[1] == "capture" && r.Method == http.MethodPost:
+		s.capturePayment(w, r, segments[0])
+	case len(segments) == 2 && segments[1] == "cancel" && r.Method == http.MethodPost:
+		s.cancelPayment(w, segments[0])
+	case len(segments) <= 2:
+		methodNotAllowed(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) listPayments(w http.ResponseWriter, r *http.Request) {
+	start, end, pageSize, nextPageNumber := pageBounds(r, len(s.paymentOrder))
+
+	payments := make([]mobilepay.Payment, 0, end-start)
+	for _, id := range s.paymentOrder[start:end] {
+		payments = append(payments, s.payments[id].Payment)
+	}
+
+	writeJSON(w, http.StatusOK, mobilepay.PaymentsRoot{
+		Payments:       payments,
+		PageSize:       pageSize,
+		NextPageNumber: nextPageNumber,
+	})
+}
+
+func (s *Server) createPayment(w http.ResponseWriter, r *http.Request) {
+	var params mobilepay.PaymentParams
+	if !decodeJSON(w, r, &params) {
+		return
+	}
+
+	switch {
+	case params.Amount <= 0:
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "Amount must be positive.")
+		return
+	case params.IdempotencyKey == "":
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "IdempotencyKey is required.")
+		return
+	case params.PaymentPointId == "":
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "PaymentPointId is required.")
+		return
+	case params.RedirectUri == "":
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "RedirectUri is required.")
+		return
+	}
+
+	if id, ok, err := s.checkIdempotency(params.IdempotencyKey, params); err != nil {
+		writeError(w, http.StatusConflict, CodeIdempotencyKeyConflict, err.Error())
+		return
+	} else if ok {
+		writeCreatedPayment(w, s.payments[id])
+		return
+	}
+
+	id := newID()
+	now := s.timestamp()
+
+	p := &payment{Payment: mobilepay.Payment{
+		PaymentId:               id,
+		Amount:                  params.Amount,
+		Description:             params.Description,
+		PaymentPointId:          params.PaymentPointId,
+		Reference:               params.Reference,
+		MobilePayAppRedirectUri: fmt.Sprintf("mobilepay://merchant_payments?payment_id=%s", id),
+		State:                   mobilepay.PaymentStateInitiated,
+		InitiatedOn:             now,
+		LastUpdatedOn:           now,
+		MerchantId:              "655ad36f-70b0-4add-a123-b943daca50e8",
+		IsoCurrencyCode:         "DKK",
+		PaymentPointName:        "mobilepaytest",
+	}}
+
+	s.payments[id] = p
+	s.paymentOrder = append(s.paymentOrder, id)
+	s.rememberIdempotency(params.IdempotencyKey, params, id)
+
+	writeCreatedPayment(w, p)
+}
+
+func writeCreatedPayment(w http.ResponseWriter, p *payment) {
+	writeJSON(w, http.StatusOK, mobilepay.CreatePaymentResponse{
+		PaymentId:               p.PaymentId,
+		MobilePayAppRedirectUri: p.MobilePayAppRedirectUri,
+	})
+}
+
+func (s *Server) getPayment(w http.ResponseWriter, paymentId string) {
+	p, ok := s.payments[paymentId]
+	if !ok {
+		writePaymentNotFound(w, paymentId)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p.Payment)
+}
+
+func (s *Server) capturePayment(w http.ResponseWriter, r *http.Request, paymentId string) {
+	p, ok := s.payments[paymentId]
+	if !ok {
+		writePaymentNotFound(w, paymentId)
+		return
+	}
+
+	var params struct {
+		Amount int `json:"amount"`
+	}
+	if !decodeJSON(w, r, &params) {
+		return
+	}
+
+	if params.Amount <= 0 {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "Amount must be positive.")
+		return
+	}
+
+	if !p.State.CanCapture() {
+		writeError(w, http.StatusConflict, CodePaymentStateInvalid,
+			fmt.Sprintf("Cannot capture a payment in state %s.", p.State))
+		return
+	}
+
+	if params.Amount > p.Amount {
+		writeError(w, http.StatusConflict, CodeAmountTooLarge, "Cannot capture a larger amount than is reserved.")
+		return
+	}
+
+	// a partial capture releases the remaining reserved amount.
+	p.capturedAmount = params.Amount
+	s.setState(p, mobilepay.PaymentStateCaptured)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) cancelPayment(w http.ResponseWriter, paymentId string) {
+	p, ok := s.payments[paymentId]
+	if !ok {
+		writePaymentNotFound(w, paymentId)
+		return
+	}
+
+	if !p.State.CanCancel() {
+		writeError(w, http.StatusConflict, CodePaymentStateInvalid,
+			fmt.Sprintf("Cannot cancel a payment in state %s.", p.State))
+		return
+	}
+
+	s.setState(p, mobilepay.PaymentStateCancelledByMerchant)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePaymentNotFound(w http.ResponseWriter, paymentId string) {
+	writeError(w, http.StatusNotFound, CodePaymentNotFound, fmt.Sprintf("Payment %s was not found.", paymentId))
+}
+
+func (s *Server) setState(p *payment, state mobilepay.PaymentState) {
+	p.State = state
+	p.LastUpdatedOn = s.timestamp()
+}
+
+// transition moves the payment to the given state as if the user or MobilePay acted on it
+// and notifies the webhooks subscribed to the event of the new state, if any.
+func (s *Server) transition(paymentId string, state mobilepay.PaymentState) error {
+	s.mu.Lock()
+
+	p, ok := s.payments[paymentId]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("mobilepaytest: payment %s was not found", paymentId)
+	}
+
+	if p.State == state {
+		s.mu.Unlock()
+		return fmt.Errorf("mobilepaytest: payment %s is already %s", paymentId, state)
+	}
+
+	if err := p.State.ValidateTransition(state); err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("mobilepaytest: payment %s: %w", paymentId, err)
+	}
+
+	s.setState(p, state)
+
+	var deliveries []*Delivery
+	switch state {
+	case mobilepay.PaymentStateReserved:
+		deliveries = s.notify(mobilepay.PaymentReserved.Name(), mobilepay.WebhookDataTypePayment, paymentId)
+	case mobilepay.PaymentStateExpired:
+		deliveries = s.notify(mobilepay.PaymentExpired.Name(), mobilepay.WebhookDataTypePayment, paymentId)
+	}
+
+	s.mu.Unlock()
+
+	s.deliver(deliveries)
+
+	return nil
+}
+
+// Reserve simulates the user accepting the payment in the MobilePay app.
+// Webhooks subscribed to payment.reserved are notified before it returns.
+func (s *Server) Reserve(paymentId string) error {
+	return s.transition(paymentId, mobilepay.PaymentStateReserved)
+}
+
+// Reject simulates the user rejecting the payment in the MobilePay app.
+func (s *Server) Reject(paymentId string) error {
+	return s.transition(paymentId, mobilepay.PaymentStateCancelledByUser)
+}
+
+// Expire simulates the payment expiring before it was accepted or captured.
+// Webhooks subscribed to payment.expired are notified before it returns.
+func (s *Server) Expire(paymentId string) error {
+	return s.transition(paymentId, mobilepay.PaymentStateExpired)
+}
+
+// CancelBySystem simulates MobilePay cancelling the payment.
+func (s *Server) CancelBySystem(paymentId string) error {
+	return s.transition(paymentId, mobilepay.PaymentStateCancelledBySystem)
+}
+
+// Payment returns the current state of the payment with the given id.
+func (s *Server) Payment(paymentId string) (mobilepay.Payment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payments[paymentId]
+	if !ok {
+		return mobilepay.Payment{}, false
+	}
+
+	return p.Payment, true
+}
+
+// CapturedAmount returns the amount captured on the payment with the given id.
+func (s *Server) CapturedAmount(paymentId string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.payments[paymentId]; ok {
+		return p.capturedAmount
+	}
+
+	return 0
+}
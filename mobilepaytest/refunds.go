@@ -0,0 +1,134 @@
+package mobilepaytest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/steffen25/mobilepay-go"
+)
+
+func (s *Server) serveRefunds(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		s.listRefunds(w, r)
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		s.createRefund(w, r)
+	case len(segments) == 0:
+		methodNotAllowed(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) listRefunds(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var createdBefore, createdAfter time.Time
+	for param, t := range map[string]*time.Time{"createdBefore": &createdBefore, "createdAfter": &createdAfter} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(mobilepay.RefundFilterTimeFormat, value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("%s must have the format yyyy-MM-ddTHH:mm.", param))
+			return
+		}
+		*t = parsed
+	}
+
+	refunds := make([]mobilepay.Refund, 0)
+	for _, refund := range s.refunds {
+		if paymentId := query.Get("paymentId"); paymentId != "" && refund.PaymentId != paymentId {
+			continue
+		}
+
+		if paymentPointId := query.Get("paymentPointId"); paymentPointId != "" && s.payments[refund.PaymentId].PaymentPointId != paymentPointId {
+			continue
+		}
+
+		createdOn := refund.CreatedOn.Time
+		if !createdBefore.IsZero() && !createdOn.Before(createdBefore) {
+			continue
+		}
+		if !createdAfter.IsZero() && !createdOn.After(createdAfter) {
+			continue
+		}
+
+		refunds = append(refunds, refund)
+	}
+
+	start, end, pageSize, nextPageNumber := pageBounds(r, len(refunds))
+
+	writeJSON(w, http.StatusOK, mobilepay.RefundsRoot{
+		Refunds:        refunds[start:end],
+		PageSize:       pageSize,
+		NextPageNumber: nextPageNumber,
+	})
+}
+
+func (s *Server) createRefund(w http.ResponseWriter, r *http.Request) {
+	var params mobilepay.RefundParams
+	if !decodeJSON(w, r, &params) {
+		return
+	}
+
+	switch {
+	case params.Amount <= 0:
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "Amount must be positive.")
+		return
+	case params.IdempotencyKey == "":
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "IdempotencyKey is required.")
+		return
+	}
+
+	if id, ok, err := s.checkIdempotency(params.IdempotencyKey, params); err != nil {
+		writeError(w, http.StatusConflict, CodeIdempotencyKeyConflict, err.Error())
+		return
+	} else if ok {
+		for _, refund := range s.refunds {
+			if refund.RefundId == id {
+				writeJSON(w, http.StatusOK, refund)
+				return
+			}
+		}
+	}
+
+	p, ok := s.payments[params.PaymentId]
+	if !ok {
+		writePaymentNotFound(w, params.PaymentId)
+		return
+	}
+
+	if !p.State.CanRefund() {
+		writeError(w, http.StatusConflict, CodePaymentStateInvalid,
+			fmt.Sprintf("Cannot refund a payment in state %s.", p.State))
+		return
+	}
+
+	remaining := p.capturedAmount - p.refundedAmount
+	if params.Amount > remaining {
+		writeError(w, http.StatusConflict, CodeRefundAmountTooLarge,
+			fmt.Sprintf("Cannot refund more than the remaining amount of %d.", remaining))
+		return
+	}
+
+	p.refundedAmount += params.Amount
+
+	refund := mobilepay.Refund{
+		RefundId:        newID(),
+		PaymentId:       params.PaymentId,
+		Amount:          params.Amount,
+		RemainingAmount: remaining - params.Amount,
+		Description:     params.Description,
+		Reference:       params.Reference,
+		CreatedOn:       s.timestamp(),
+	}
+
+	s.refunds = append(s.refunds, refund)
+	s.rememberIdempotency(params.IdempotencyKey, params, refund.RefundId)
+
+	writeJSON(w, http.StatusOK, refund)
+}
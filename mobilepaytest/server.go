@@ -0,0 +1,228 @@
+// Package mobilepaytest provides an in-process fake of the MobilePay App Payments API
+// for testing code that uses the mobilepay client.
+//
+// The fake keeps payments, refunds and webhooks in memory and follows the same state
+// machine as MobilePay: a payment is initiated when created, reserved when the user
+// accepts it in the app, and can then be captured (fully or partially), cancelled or
+// expire. Captured payments can be refunded up to the captured amount. Invalid operations
+// are answered with the same status codes and ConflictError codes as the real API.
+//
+// Webhooks registered through the API receive signed notifications when a payment is
+// reserved or expires, when a payment point is activated and when a test notification is published.
+//
+//	srv := mobilepaytest.NewServer()
+//	defer srv.Close()
+//
+//	mp := mobilepay.New("client_id", "api_key", &mobilepay.Config{URL: srv.URL})
+package mobilepaytest
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steffen25/mobilepay-go"
+)
+
+// Error codes returned in the ConflictError of failed requests.
+const (
+	CodeInvalidRequest         = string(mobilepay.ErrorCodeInvalidRequest)
+	CodeUnauthorized           = string(mobilepay.ErrorCodeUnauthorized)
+	CodePaymentNotFound        = string(mobilepay.ErrorCodePaymentNotFound)
+	CodeWebhookNotFound        = string(mobilepay.ErrorCodeWebhookNotFound)
+	CodeAmountTooLarge         = string(mobilepay.ErrorCodeAmountTooLarge)
+	CodePaymentStateInvalid    = string(mobilepay.ErrorCodePaymentStateInvalid)
+	CodeRefundAmountTooLarge   = string(mobilepay.ErrorCodeRefundAmountTooLarge)
+	CodeIdempotencyKeyConflict = string(mobilepay.ErrorCodeDuplicateIdempotencyKey)
+)
+
+// Server is a fake MobilePay API server. Point a mobilepay client at it by using its URL
+// as Config.URL.
+type Server struct {
+	// URL is the base url of the fake API.
+	URL string
+
+	// WebhookHTTPClient is used to deliver webhook notifications. http.DefaultClient is used if nil.
+	WebhookHTTPClient *http.Client
+
+	server *httptest.Server
+
+	mu           sync.Mutex
+	payments     map[string]*payment
+	paymentOrder []string
+	refunds      []mobilepay.Refund
+	webhooks     map[string]*mobilepay.Webhook
+	webhookOrder []string
+	idempotency  map[string]idempotentResult
+	now          func() time.Time
+	deliveries   []Delivery
+	held         []*Delivery
+	holding      bool
+}
+
+type payment struct {
+	mobilepay.Payment
+	capturedAmount int
+	refundedAmount int
+}
+
+// idempotentResult remembers the request and resource created for an idempotency key.
+type idempotentResult struct {
+	request    string
+	resourceId string
+}
+
+// NewServer starts and returns a new fake MobilePay API server.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		payments:    make(map[string]*payment),
+		webhooks:    make(map[string]*mobilepay.Webhook),
+		idempotency: make(map[string]idempotentResult),
+		now:         time.Now,
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a mobilepay client configured to use the fake server.
+func (s *Server) Client() *mobilepay.Client {
+	return mobilepay.New("client_id", "api_key", &mobilepay.Config{URL: s.URL})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" || r.Header.Get("x-ibm-client-id") == "" {
+		writeError(w, http.StatusUnauthorized, CodeUnauthorized, "Missing api key or client id.")
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "v1" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch segments[1] {
+	case "payments":
+		s.servePayments(w, r, segments[2:])
+	case "refunds":
+		s.serveRefunds(w, r, segments[2:])
+	case "webhooks":
+		s.serveWebhooks(w, r, segments[2:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) timestamp() mobilepay.Timestamp {
+	return mobilepay.Timestamp{Time: s.now().UTC().Truncate(time.Second)}
+}
+
+// checkIdempotency returns the id of the resource already created for key, or an error
+// if key was used for a different request.
+func (s *Server) checkIdempotency(key string, request interface{}) (string, bool, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", false, err
+	}
+
+	result, ok := s.idempotency[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	if result.request != string(data) {
+		return "", false, fmt.Errorf("idempotency key %s was already used for a different request", key)
+	}
+
+	return result.resourceId, true, nil
+}
+
+func (s *Server) rememberIdempotency(key string, request interface{}, resourceId string) {
+	data, _ := json.Marshal(request)
+	s.idempotency[key] = idempotentResult{request: string(data), resourceId: resourceId}
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "The request body is not valid json.")
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, mobilepay.ConflictError{
+		Code:          code,
+		Message:       message,
+		CorrelationID: newID(),
+		Origin:        "MPY",
+	})
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// pageBounds returns the slice bounds and next page number for the pageSize and pageNumber
+// query parameters of r.
+func pageBounds(r *http.Request, total int) (start, end, pageSize, nextPageNumber int) {
+	pageSize, _ = strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	pageNumber, _ := strconv.Atoi(r.URL.Query().Get("pageNumber"))
+	if pageNumber <= 0 {
+		pageNumber = 1
+	}
+
+	start = (pageNumber - 1) * pageSize
+	if start > total {
+		start = total
+	}
+
+	end = start + pageSize
+	if end >= total {
+		return start, total, pageSize, 0
+	}
+
+	return start, end, pageSize, pageNumber + 1
+}
+
+// newID returns a random UUID (version 4).
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,258 @@
+package mobilepaytest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/steffen25/mobilepay-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPaymentParams(idempotencyKey string) *mobilepay.PaymentParams {
+	return &mobilepay.PaymentParams{
+		Amount:         1050,
+		IdempotencyKey: idempotencyKey,
+		PaymentPointId: "1f8ed17f-f310-4f40-a7a4-df78185efbdd",
+		RedirectUri:    "app://callback",
+		Reference:      "order-1",
+		Description:    "this is a test payment",
+	}
+}
+
+func conflictCode(t *testing.T, err error) string {
+	mpError, ok := err.(*mobilepay.ErrorResponse)
+	if !assert.True(t, ok, "expected an ErrorResponse, got %v", err) || !assert.NotNil(t, mpError.Conflict) {
+		return ""
+	}
+
+	return mpError.Conflict.Code
+}
+
+func TestServer_Payment_Lifecycle(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	mp := mobilepay.New("client_id", "api_key", &mobilepay.Config{URL: srv.URL})
+	ctx := context.TODO()
+
+	created, err := mp.Payment.Create(ctx, newPaymentParams("7347ba06-95c5-4181-82e5-7c7a23609a0e"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, created.PaymentId)
+
+	payment, err := mp.Payment.Find(ctx, created.PaymentId)
+	assert.Nil(t, err)
+	assert.Equal(t, mobilepay.PaymentStateInitiated, payment.State)
+	assert.Equal(t, 1050, payment.Amount)
+
+	err = mp.Payment.Capture(ctx, created.PaymentId, 1050)
+	assert.Equal(t, CodePaymentStateInvalid, conflictCode(t, err))
+
+	assert.Nil(t, srv.Reserve(created.PaymentId))
+
+	err = mp.Payment.Capture(ctx, created.PaymentId, 2000)
+	assert.Equal(t, CodeAmountTooLarge, conflictCode(t, err))
+
+	err = mp.Payment.Capture(ctx, created.PaymentId, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, 1000, srv.CapturedAmount(created.PaymentId))
+
+	payment, err = mp.Payment.Find(ctx, created.PaymentId)
+	assert.Nil(t, err)
+	assert.Equal(t, mobilepay.PaymentStateCaptured, payment.State)
+
+	err = mp.Payment.Cancel(ctx, created.PaymentId)
+	assert.Equal(t, CodePaymentStateInvalid, conflictCode(t, err))
+}
+
+func TestServer_Refunds(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	mp := srv.Client()
+	ctx := context.TODO()
+
+	created, err := mp.Payment.Create(ctx, newPaymentParams("7347ba06-95c5-4181-82e5-7c7a23609a0e"))
+	assert.Nil(t, err)
+
+	refundParams := &mobilepay.RefundParams{
+		IdempotencyKey: "7576910d-9789-4fef-a72e-877d89afec94",
+		PaymentId:      created.PaymentId,
+		Amount:         600,
+		Reference:      "refund-1",
+	}
+
+	_, err = mp.Payment.Refund.Create(ctx, refundParams)
+	assert.Equal(t, CodePaymentStateInvalid, conflictCode(t, err))
+
+	assert.Nil(t, srv.Reserve(created.PaymentId))
+	assert.Nil(t, mp.Payment.Capture(ctx, created.PaymentId, 1000))
+
+	refund, err := mp.Payment.Refund.Create(ctx, refundParams)
+	assert.Nil(t, err)
+	assert.Equal(t, 600, refund.Amount)
+	assert.Equal(t, 400, refund.RemainingAmount)
+
+	// retrying with the same idempotency key returns the same refund.
+	again, err := mp.Payment.Refund.Create(ctx, refundParams)
+	assert.Nil(t, err)
+	assert.Equal(t, refund.RefundId, again.RefundId)
+
+	_, err = mp.Payment.Refund.Create(ctx, &mobilepay.RefundParams{
+		IdempotencyKey: "8576910d-9789-4fef-a72e-877d89afec94",
+		PaymentId:      created.PaymentId,
+		Amount:         500,
+	})
+	assert.Equal(t, CodeRefundAmountTooLarge, conflictCode(t, err))
+
+	refunds, err := mp.Payment.Refund.List(ctx, &mobilepay.RefundsListOptions{
+		ListOptions: mobilepay.ListOptions{PageSize: 10, PageNumber: 1},
+		PaymentId:   created.PaymentId,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, refunds.Refunds, 1)
+	assert.Equal(t, 0, refunds.NextPageNumber)
+}
+
+func TestServer_Idempotent_Create(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	mp := srv.Client()
+	ctx := context.TODO()
+
+	first, err := mp.Payment.Create(ctx, newPaymentParams("7347ba06-95c5-4181-82e5-7c7a23609a0e"))
+	assert.Nil(t, err)
+
+	second, err := mp.Payment.Create(ctx, newPaymentParams("7347ba06-95c5-4181-82e5-7c7a23609a0e"))
+	assert.Nil(t, err)
+	assert.Equal(t, first.PaymentId, second.PaymentId)
+
+	params := newPaymentParams("7347ba06-95c5-4181-82e5-7c7a23609a0e")
+	params.Amount = 2000
+	_, err = mp.Payment.Create(ctx, params)
+	assert.Equal(t, CodeIdempotencyKeyConflict, conflictCode(t, err))
+}
+
+func TestServer_Expire_And_Cancel(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	mp := srv.Client()
+	ctx := context.TODO()
+
+	expiring, err := mp.Payment.Create(ctx, newPaymentParams("7347ba06-95c5-4181-82e5-7c7a23609a0e"))
+	assert.Nil(t, err)
+	assert.Nil(t, srv.Reserve(expiring.PaymentId))
+	assert.Nil(t, srv.Expire(expiring.PaymentId))
+	assert.Error(t, srv.Reserve(expiring.PaymentId))
+
+	err = mp.Payment.Capture(ctx, expiring.PaymentId, 1050)
+	assert.Equal(t, CodePaymentStateInvalid, conflictCode(t, err))
+
+	cancelled, err := mp.Payment.Create(ctx, newPaymentParams("8347ba06-95c5-4181-82e5-7c7a23609a0e"))
+	assert.Nil(t, err)
+	assert.Nil(t, mp.Payment.Cancel(ctx, cancelled.PaymentId))
+
+	payment, ok := srv.Payment(cancelled.PaymentId)
+	assert.True(t, ok)
+	assert.Equal(t, mobilepay.PaymentStateCancelledByMerchant, payment.State)
+}
+
+func TestServer_Not_Found(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	mp := srv.Client()
+	ctx := context.TODO()
+
+	_, err := mp.Payment.Find(ctx, "186d2b31-ff25-4414-9fd1-bfe9807fa8b7")
+	assert.Equal(t, CodePaymentNotFound, conflictCode(t, err))
+	assert.Equal(t, http.StatusNotFound, err.(*mobilepay.ErrorResponse).StatusCode)
+
+	_, err = mp.Webhook.Find(ctx, "e4a2e195-74f6-42e1-a172-83291c9d2a41")
+	assert.Equal(t, CodeWebhookNotFound, conflictCode(t, err))
+
+	assert.Error(t, srv.Reserve("186d2b31-ff25-4414-9fd1-bfe9807fa8b7"))
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	mp := mobilepay.New("", "api_key", &mobilepay.Config{URL: srv.URL})
+
+	_, err := mp.Payment.Get(context.TODO(), mobilepay.ListOptions{PageSize: 10, PageNumber: 1})
+	assert.Equal(t, CodeUnauthorized, conflictCode(t, err))
+}
+
+func TestServer_List_Payments(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	mp := srv.Client()
+	ctx := context.TODO()
+
+	for _, key := range []string{
+		"1347ba06-95c5-4181-82e5-7c7a23609a0e",
+		"2347ba06-95c5-4181-82e5-7c7a23609a0e",
+		"3347ba06-95c5-4181-82e5-7c7a23609a0e",
+	} {
+		_, err := mp.Payment.Create(ctx, newPaymentParams(key))
+		assert.Nil(t, err)
+	}
+
+	root, err := mp.Payment.Get(ctx, mobilepay.ListOptions{PageSize: 2, PageNumber: 1})
+	assert.Nil(t, err)
+	assert.Len(t, root.Payments, 2)
+	assert.Equal(t, 2, root.NextPageNumber)
+
+	it := mp.Payment.All(ctx, mobilepay.ListOptions{PageSize: 2})
+	count := 0
+	for it.Next() {
+		count++
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 3, count)
+}
+
+func TestServer_Webhooks(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	mp := srv.Client()
+	ctx := context.TODO()
+
+	webhook, err := mp.Webhook.Create(ctx, &mobilepay.WebhookCreateParams{
+		Events: []mobilepay.WebhookEvent{mobilepay.PaymentReserved.Name()},
+		Url:    "https://my-api.com/webhooks",
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, webhook.SignatureKey)
+
+	updated, err := mp.Webhook.Update(ctx, webhook.WebhookId, &mobilepay.WebhookUpdateParams{
+		Events: []mobilepay.WebhookEvent{mobilepay.PaymentExpired.Name()},
+		Url:    "https://my-api.com/webhooks/v2",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "https://my-api.com/webhooks/v2", updated.Url)
+	assert.Equal(t, webhook.SignatureKey, updated.SignatureKey)
+
+	root, err := mp.Webhook.Get(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, root.Webhooks, 1)
+
+	assert.Nil(t, mp.Webhook.Delete(ctx, webhook.WebhookId))
+
+	root, err = mp.Webhook.Get(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, root.Webhooks, 0)
+
+	_, err = mp.Webhook.Create(ctx, &mobilepay.WebhookCreateParams{
+		Events: []mobilepay.WebhookEvent{"payment.unknown"},
+		Url:    "https://my-api.com/webhooks",
+	})
+	var validationErr *mobilepay.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+}
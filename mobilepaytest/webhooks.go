@@ -0,0 +1,146 @@
+package mobilepaytest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/steffen25/mobilepay-go"
+)
+
+func (s *Server) serveWebhooks(w http.ResponseWriter, r *http.Request, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		s.listWebhooks(w)
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		s.createWebhook(w, r)
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		s.getWebhook(w, segments[0])
+	case len(segments) == 1 && r.Method == http.MethodPut:
+		s.updateWebhook(w, r, segments[0])
+	case len(segments) == 1 && r.Method == http.MethodDelete:
+		s.deleteWebhook(w, segments[0])
+	case len(segments) == 2 && segments[1] == "publishtestnotification" && r.Method == http.MethodPost:
+		s.publishTestNotification(w, segments[0])
+	case len(segments) <= 1:
+		methodNotAllowed(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) listWebhooks(w http.ResponseWriter) {
+	webhooks := make([]mobilepay.Webhook, 0, len(s.webhookOrder))
+	for _, id := range s.webhookOrder {
+		webhooks = append(webhooks, *s.webhooks[id])
+	}
+
+	writeJSON(w, http.StatusOK, mobilepay.WebhooksRoot{Webhooks: webhooks})
+}
+
+// validWebhook reports whether url and events describe a valid webhook. Unlike MobilePay the
+// fake accepts plain http urls, so notifications can be delivered to local test servers.
+func validWebhook(w http.ResponseWriter, url string, events []mobilepay.WebhookEvent) bool {
+	if url == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "Url is required.")
+		return false
+	}
+
+	if len(events) == 0 {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "At least one event is required.")
+		return false
+	}
+
+	for _, event := range events {
+		if event.Enum() == mobilepay.Unknown {
+			writeError(w, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("Unknown event %s.", event))
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var params mobilepay.WebhookCreateParams
+	if !decodeJSON(w, r, &params) || !validWebhook(w, params.Url, params.Events) {
+		return
+	}
+
+	webhook := &mobilepay.Webhook{
+		WebhookId:    newID(),
+		SignatureKey: newID(),
+		Url:          params.Url,
+		Events:       params.Events,
+	}
+
+	s.webhooks[webhook.WebhookId] = webhook
+	s.webhookOrder = append(s.webhookOrder, webhook.WebhookId)
+
+	writeJSON(w, http.StatusOK, webhook)
+}
+
+func (s *Server) getWebhook(w http.ResponseWriter, webhookId string) {
+	webhook, ok := s.webhooks[webhookId]
+	if !ok {
+		writeWebhookNotFound(w, webhookId)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhook)
+}
+
+func (s *Server) updateWebhook(w http.ResponseWriter, r *http.Request, webhookId string) {
+	webhook, ok := s.webhooks[webhookId]
+	if !ok {
+		writeWebhookNotFound(w, webhookId)
+		return
+	}
+
+	var params mobilepay.WebhookUpdateParams
+	if !decodeJSON(w, r, &params) || !validWebhook(w, params.Url, params.Events) {
+		return
+	}
+
+	webhook.Url = params.Url
+	webhook.Events = params.Events
+
+	writeJSON(w, http.StatusOK, webhook)
+}
+
+func (s *Server) deleteWebhook(w http.ResponseWriter, webhookId string) {
+	if _, ok := s.webhooks[webhookId]; !ok {
+		writeWebhookNotFound(w, webhookId)
+		return
+	}
+
+	delete(s.webhooks, webhookId)
+	for i, id := range s.webhookOrder {
+		if id == webhookId {
+			s.webhookOrder = append(s.webhookOrder[:i], s.webhookOrder[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// publishTestNotification sends a test.notification to the webhook. Like MobilePay it answers
+// before the notification is delivered.
+func (s *Server) publishTestNotification(w http.ResponseWriter, webhookId string) {
+	webhook, ok := s.webhooks[webhookId]
+	if !ok {
+		writeWebhookNotFound(w, webhookId)
+		return
+	}
+
+	deliveries := s.notifyWebhook(webhook, mobilepay.TestNotification.Name(), mobilepay.WebhookDataTypeTest, newID())
+
+	// s.mu is held until the request has been answered.
+	go s.deliver(deliveries)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeWebhookNotFound(w http.ResponseWriter, webhookId string) {
+	writeError(w, http.StatusNotFound, CodeWebhookNotFound, fmt.Sprintf("Webhook %s was not found.", webhookId))
+}
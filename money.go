@@ -0,0 +1,331 @@
+package mobilepay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code supported by MobilePay, as found in Payment.IsoCurrencyCode.
+type Currency string
+
+// Currencies supported by MobilePay.
+const (
+	DKK Currency = "DKK"
+	EUR Currency = "EUR"
+	NOK Currency = "NOK"
+)
+
+// ErrCurrencyMismatch is returned when combining amounts of different currencies.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// IsKnown reports whether c is a currency supported by MobilePay.
+func (c Currency) IsKnown() bool {
+	switch c {
+	case DKK, EUR, NOK:
+		return true
+	}
+
+	return false
+}
+
+// Money is an amount in minor units, e.g. øre or cents, of a currency.
+//
+// The amounts of the MobilePay API are integers in minor units. Pass a Money to them with
+// PaymentServiceOp.CaptureMoney, PaymentParams.SetMoney or RefundParams.SetMoney, and read them
+// with Payment.Money, Refund.Money or Refund.RemainingMoney. Money is marshalled to JSON as
+// that integer.
+type Money struct {
+	minor    int64
+	currency Currency
+}
+
+// NewMoney returns an amount of minor units, e.g. NewMoney(1050, DKK) is 10,50 kr.
+func NewMoney(minor int64, currency Currency) (Money, error) {
+	if !currency.IsKnown() {
+		return Money{}, newArgError("currency", fmt.Sprintf("%q is not supported", currency))
+	}
+
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// MoneyFromMajor returns an amount of major units, e.g. MoneyFromMajor(10, DKK) is 10,00 kr.
+func MoneyFromMajor(major int64, currency Currency) (Money, error) {
+	if major > math.MaxInt64/100 || major < math.MinInt64/100 {
+		return Money{}, newArgError("major", "the amount is too large")
+	}
+
+	return NewMoney(major*100, currency)
+}
+
+// ParseMoney parses a decimal amount of major units like "10.50", "10,50" or "-3". Amounts with
+// more than two decimals are rejected rather than rounded.
+func ParseMoney(s string, currency Currency) (Money, error) {
+	value := strings.TrimSpace(s)
+
+	negative := strings.HasPrefix(value, "-")
+	if negative || strings.HasPrefix(value, "+") {
+		value = value[1:]
+	}
+
+	major, fraction := value, ""
+	if i := strings.IndexAny(value, ".,"); i >= 0 {
+		major, fraction = value[:i], value[i+1:]
+		if fraction == "" {
+			return Money{}, newArgError("amount", fmt.Sprintf("%q is not a decimal amount", s))
+		}
+	}
+
+	if major == "" || !isDigits(major) || !isDigits(fraction) {
+		return Money{}, newArgError("amount", fmt.Sprintf("%q is not a decimal amount", s))
+	}
+
+	if len(fraction) > 2 {
+		return Money{}, newArgError("amount", fmt.Sprintf("%q has more than two decimals", s))
+	}
+
+	cents, _ := strconv.ParseInt((fraction + "00")[:2], 10, 64)
+
+	units, err := strconv.ParseInt(major, 10, 64)
+	if err != nil || units > (math.MaxInt64-cents)/100 {
+		return Money{}, newArgError("amount", fmt.Sprintf("%q is too large", s))
+	}
+
+	minor := units*100 + cents
+	if negative {
+		minor = -minor
+	}
+
+	return NewMoney(minor, currency)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MinorUnits returns the amount in minor units as used by the MobilePay API.
+func (m Money) MinorUnits() int64 {
+	return m.minor
+}
+
+// int returns the amount in minor units as the int used by the fields of the MobilePay API.
+// It fails if the amount does not fit, which can only happen where int is 32 bits.
+func (m Money) int() (int, error) {
+	if int64(int(m.minor)) != m.minor {
+		return 0, newArgError("amount", "the amount is too large")
+	}
+
+	return int(m.minor), nil
+}
+
+// Currency returns the currency of the amount.
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsNegative reports whether the amount is less than zero.
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// Add returns m + other. It fails with ErrCurrencyMismatch if the currencies differ.
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	if (other.minor > 0 && m.minor > math.MaxInt64-other.minor) ||
+		(other.minor < 0 && m.minor < math.MinInt64-other.minor) {
+		return Money{}, newArgError("amount", "the sum overflows")
+	}
+
+	return Money{minor: m.minor + other.minor, currency: m.currency}, nil
+}
+
+// Sub returns m - other. It fails with ErrCurrencyMismatch if the currencies differ.
+func (m Money) Sub(other Money) (Money, error) {
+	if other.minor == math.MinInt64 {
+		return Money{}, newArgError("amount", "the difference overflows")
+	}
+
+	return m.Add(Money{minor: -other.minor, currency: other.currency})
+}
+
+// Cmp compares m and other and returns -1, 0 or +1. It fails with ErrCurrencyMismatch if the
+// currencies differ.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.minor < other.minor:
+		return -1, nil
+	case m.minor > other.minor:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.currency != other.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+
+	return nil
+}
+
+// Decimal returns the amount in major units with two decimals and a dot, e.g. "10.50".
+func (m Money) Decimal() string {
+	major, minor := m.split()
+
+	return fmt.Sprintf("%s%d.%02d", m.sign(), major, minor)
+}
+
+// String formats the amount the way it is written in the country of its currency,
+// e.g. "1.234,50 kr." for DKK, "kr 1 234,50" for NOK and "1 234,50 €" for EUR.
+func (m Money) String() string {
+	major, minor := m.split()
+
+	switch m.currency {
+	case DKK:
+		return fmt.Sprintf("%s%s,%02d kr.", m.sign(), groupThousands(major, "."), minor)
+	case NOK:
+		return fmt.Sprintf("%skr %s,%02d", m.sign(), groupThousands(major, " "), minor)
+	case EUR:
+		return fmt.Sprintf("%s%s,%02d €", m.sign(), groupThousands(major, " "), minor)
+	default:
+		return fmt.Sprintf("%s %s", m.Decimal(), m.currency)
+	}
+}
+
+// split returns the absolute major and minor units of the amount.
+func (m Money) split() (uint64, uint64) {
+	abs := uint64(m.minor)
+	if m.minor < 0 {
+		abs = uint64(-(m.minor + 1)) + 1
+	}
+
+	return abs / 100, abs % 100
+}
+
+func (m Money) sign() string {
+	if m.minor < 0 {
+		return "-"
+	}
+
+	return ""
+}
+
+// groupThousands formats n with sep between groups of three digits.
+func groupThousands(n uint64, sep string) string {
+	digits := strconv.FormatUint(n, 10)
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+
+	return b.String()
+}
+
+// MarshalJSON encodes the amount as an integer in minor units, like the amounts of the MobilePay API.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(m.minor, 10)), nil
+}
+
+// UnmarshalJSON decodes an integer in minor units, keeping the currency of m. It also decodes an
+// object like a payment, taking the minor units from its "amount" field and the currency from the
+// sibling "isoCurrencyCode" field.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var amount struct {
+			Amount          *int64   `json:"amount"`
+			IsoCurrencyCode Currency `json:"isoCurrencyCode"`
+		}
+		if err := json.Unmarshal(trimmed, &amount); err != nil {
+			return err
+		}
+
+		if amount.Amount == nil {
+			return newArgError("amount", "it is missing")
+		}
+
+		decoded, err := NewMoney(*amount.Amount, amount.IsoCurrencyCode)
+		if err != nil {
+			return err
+		}
+
+		*m = decoded
+
+		return nil
+	}
+
+	var minor int64
+	if err := json.Unmarshal(data, &minor); err != nil {
+		return err
+	}
+
+	m.minor = minor
+
+	return nil
+}
+
+// Money returns the amount of the payment in its currency.
+func (p Payment) Money() (Money, error) {
+	return NewMoney(int64(p.Amount), Currency(p.IsoCurrencyCode))
+}
+
+// SetMoney sets the amount of the payment to amount.
+func (p *PaymentParams) SetMoney(amount Money) error {
+	minor, err := amount.int()
+	if err != nil {
+		return err
+	}
+
+	p.Amount = minor
+
+	return nil
+}
+
+// Money returns the refunded amount in currency, the currency of the refunded payment.
+func (r Refund) Money(currency Currency) (Money, error) {
+	return NewMoney(int64(r.Amount), currency)
+}
+
+// RemainingMoney returns the amount of the payment that can still be refunded in currency, the
+// currency of the refunded payment.
+func (r Refund) RemainingMoney(currency Currency) (Money, error) {
+	return NewMoney(int64(r.RemainingAmount), currency)
+}
+
+// SetMoney sets the amount to refund to amount.
+func (p *RefundParams) SetMoney(amount Money) error {
+	minor, err := amount.int()
+	if err != nil {
+		return err
+	}
+
+	p.Amount = minor
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package mobilepay
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustMoney(t *testing.T, minor int64, currency Currency) Money {
+	m, err := NewMoney(minor, currency)
+	assert.Nil(t, err)
+	return m
+}
+
+func TestNewMoney(t *testing.T) {
+	m, err := NewMoney(1050, DKK)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1050), m.MinorUnits())
+	assert.Equal(t, DKK, m.Currency())
+
+	_, err = NewMoney(1050, Currency("SEK"))
+	assert.Error(t, err)
+}
+
+func TestMoneyFromMajor(t *testing.T) {
+	m, err := MoneyFromMajor(10, EUR)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000), m.MinorUnits())
+
+	_, err = MoneyFromMajor(math.MaxInt64, EUR)
+	assert.Error(t, err)
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := map[string]int64{
+		"10.50":  1050,
+		"10,50":  1050,
+		"10.5":   1050,
+		"10":     1000,
+		"0.01":   1,
+		"-3,25":  -325,
+		"+7":     700,
+		" 1.00 ": 100,
+
+		"92233720368547758.07": math.MaxInt64,
+	}
+	for input, minor := range tests {
+		m, err := ParseMoney(input, DKK)
+		assert.Nil(t, err, input)
+		assert.Equal(t, minor, m.MinorUnits(), input)
+	}
+
+	for _, input := range []string{"", "abc", "10.", ".50", "10.505", "1.000,50", "1e3", "--1", "-+5", "+-5", "99999999999999999999", "92233720368547758.99", "-92233720368547758.08"} {
+		_, err := ParseMoney(input, DKK)
+		assert.Error(t, err, input)
+	}
+
+	_, err := ParseMoney("10.50", Currency("USD"))
+	assert.Error(t, err)
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	a := mustMoney(t, 1050, DKK)
+	b := mustMoney(t, 250, DKK)
+
+	sum, err := a.Add(b)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1300), sum.MinorUnits())
+
+	diff, err := b.Sub(a)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-800), diff.MinorUnits())
+	assert.True(t, diff.IsNegative())
+
+	cmp, err := a.Cmp(b)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cmp)
+
+	zero, err := a.Sub(a)
+	assert.Nil(t, err)
+	assert.True(t, zero.IsZero())
+
+	_, err = a.Add(mustMoney(t, 100, EUR))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = a.Sub(mustMoney(t, 100, NOK))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = a.Cmp(mustMoney(t, 100, NOK))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = mustMoney(t, math.MaxInt64, DKK).Add(mustMoney(t, 1, DKK))
+	assert.Error(t, err)
+}
+
+func TestMoney_Format(t *testing.T) {
+	assert.Equal(t, "10,50 kr.", mustMoney(t, 1050, DKK).String())
+	assert.Equal(t, "1.234.567,05 kr.", mustMoney(t, 123456705, DKK).String())
+	assert.Equal(t, "-0,05 kr.", mustMoney(t, -5, DKK).String())
+	assert.Equal(t, "kr 1 234,50", mustMoney(t, 123450, NOK).String())
+	assert.Equal(t, "1 234,50 €", mustMoney(t, 123450, EUR).String())
+	assert.Equal(t, "10.50", mustMoney(t, 1050, EUR).Decimal())
+	assert.Equal(t, "-92233720368547758.08", mustMoney(t, math.MinInt64, DKK).Decimal())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	type capture struct {
+		Amount Money `json:"amount"`
+	}
+
+	data, err := json.Marshal(capture{Amount: mustMoney(t, 1050, DKK)})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"amount":1050}`, string(data))
+
+	decoded := capture{Amount: mustMoney(t, 0, DKK)}
+	assert.Nil(t, json.Unmarshal([]byte(`{"amount":2500}`), &decoded))
+	assert.Equal(t, int64(2500), decoded.Amount.MinorUnits())
+	assert.Equal(t, DKK, decoded.Amount.Currency())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"25.00"}`), &decoded))
+
+	var m Money
+	assert.Nil(t, json.Unmarshal([]byte(`{"paymentId":"1","amount":1250,"isoCurrencyCode":"EUR"}`), &m))
+	assert.Equal(t, "12,50 €", m.String())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":1250}`), &m))
+	assert.Error(t, json.Unmarshal([]byte(`{"isoCurrencyCode":"DKK"}`), &m))
+}
+
+func TestPayment_Money(t *testing.T) {
+	m, err := Payment{Amount: 1250, IsoCurrencyCode: "DKK"}.Money()
+	assert.Nil(t, err)
+	assert.Equal(t, "12,50 kr.", m.String())
+
+	_, err = Payment{Amount: 1250}.Money()
+	assert.Error(t, err)
+}
+
+func TestRefund_Money(t *testing.T) {
+	refund := Refund{Amount: 250, RemainingAmount: 1000}
+
+	m, err := refund.Money(DKK)
+	assert.Nil(t, err)
+	assert.Equal(t, "2,50 kr.", m.String())
+
+	m, err = refund.RemainingMoney(DKK)
+	assert.Nil(t, err)
+	assert.Equal(t, "10,00 kr.", m.String())
+
+	params := &RefundParams{}
+	assert.Nil(t, params.SetMoney(mustMoney(t, 250, DKK)))
+	assert.Equal(t, 250, params.Amount)
+
+	payment := &PaymentParams{}
+	assert.Nil(t, payment.SetMoney(mustMoney(t, 1050, DKK)))
+	assert.Equal(t, 1050, payment.Amount)
+}
@@ -0,0 +1,25 @@
+package mobilepay
+
+import "context"
+
+// Operation identifies the MobilePay API operation a request is sent for.
+type Operation struct {
+	// Name is the service method, e.g. "Payment.Capture".
+	Name string
+
+	// Route is the path template of the endpoint, e.g. "v1/payments/{id}/capture".
+	Route string
+}
+
+type operationContextKey struct{}
+
+// withOperation returns a copy of ctx carrying the operation of the service method being called.
+func withOperation(ctx context.Context, name, route string) context.Context {
+	return context.WithValue(ctx, operationContextKey{}, Operation{Name: name, Route: route})
+}
+
+// OperationFromContext returns the operation stored in ctx by a service method, if any.
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	op, ok := ctx.Value(operationContextKey{}).(Operation)
+	return op, ok
+}
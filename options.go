@@ -0,0 +1,239 @@
+package mobilepay
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WithBaseURL sets the base URL of the MobilePay API, e.g. DefaultBaseURL or TestBaseUrl.
+// The URL must be absolute.
+func WithBaseURL(baseURL string) ClientOpt {
+	return func(c *Client) error {
+		if baseURL == "" {
+			return newArgError("baseURL", "cannot be empty")
+		}
+
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return newArgError("baseURL", err.Error())
+		}
+
+		if u.Scheme == "" || u.Host == "" {
+			return newArgError("baseURL", "it must be an absolute URL")
+		}
+
+		// make sure relative API paths are resolved below the path of the base URL.
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+
+		c.BaseURL = u
+
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to communicate with the MobilePay API.
+func WithHTTPClient(httpClient *http.Client) ClientOpt {
+	return func(c *Client) error {
+		if httpClient == nil {
+			return newArgError("httpClient", "cannot be nil")
+		}
+
+		c.client = httpClient
+
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of the HTTP client. It is applied after all other options,
+// regardless of the order they are given in, to a copy of the HTTP client, so a client
+// passed to WithHTTPClient is left untouched.
+func WithTimeout(timeout time.Duration) ClientOpt {
+	return func(c *Client) error {
+		if timeout <= 0 {
+			return newArgError("timeout", "it must be positive")
+		}
+
+		c.timeout = timeout
+
+		return nil
+	}
+}
+
+// WithLogger sets the logger used by the client.
+func WithLogger(logger LeveledLoggerInterface) ClientOpt {
+	return func(c *Client) error {
+		if logger == nil {
+			return newArgError("logger", "cannot be nil")
+		}
+
+		c.Logger = logger
+
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) ClientOpt {
+	return func(c *Client) error {
+		if strings.TrimSpace(ua) == "" {
+			return newArgError("userAgent", "cannot be empty")
+		}
+
+		c.UserAgent = ua
+
+		return nil
+	}
+}
+
+// WithHeader sets an extra HTTP header on every request to the API. It replaces a header
+// of the same name regardless of its case.
+func WithHeader(key, value string) ClientOpt {
+	return func(c *Client) error {
+		if strings.TrimSpace(key) == "" {
+			return newArgError("header", "the key cannot be empty")
+		}
+
+		key = http.CanonicalHeaderKey(key)
+		for k := range c.headers {
+			if http.CanonicalHeaderKey(k) == key {
+				delete(c.headers, k)
+			}
+		}
+
+		c.headers[key] = value
+
+		return nil
+	}
+}
+
+// WithRequestCompletedCallback sets a function that is called after every request
+// made to the MobilePay API.
+func WithRequestCompletedCallback(callback RequestCompletionCallback) ClientOpt {
+	return func(c *Client) error {
+		c.onRequestCompleted = callback
+
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests. A nil policy disables retries.
+func WithRetryPolicy(policy *RetryPolicy) ClientOpt {
+	return func(c *Client) error {
+		if policy != nil && (policy.MinBackoff < 0 || policy.MaxBackoff < 0 || policy.MaxElapsedTime < 0) {
+			return newArgError("retryPolicy", "durations cannot be negative")
+		}
+
+		c.retryPolicy = policy
+
+		return nil
+	}
+}
+
+// WithIdempotencyKeyProvider sets the provider used to fill in the idempotency key of payments
+// and refunds that are created without one.
+func WithIdempotencyKeyProvider(provider IdempotencyKeyProvider) ClientOpt {
+	return func(c *Client) error {
+		c.idempotencyKeyProvider = provider
+
+		return nil
+	}
+}
+
+// WithGeneratedIdempotencyKeys makes the client generate a random UUID (version 4) as the
+// idempotency key of payments and refunds that are created without one.
+func WithGeneratedIdempotencyKeys() ClientOpt {
+	return WithIdempotencyKeyProvider(RandomIdempotencyKeys)
+}
+
+// WithRedactedFields adds headers, JSON fields and query parameters whose values are masked in
+// logs and error messages. Authorization, x-ibm-client-id and signatureKey are always masked.
+func WithRedactedFields(fields ...string) ClientOpt {
+	return func(c *Client) error {
+		for _, field := range fields {
+			if strings.TrimSpace(field) == "" {
+				return newArgError("redactedFields", "a field cannot be empty")
+			}
+		}
+
+		c.redactor.addFields(fields...)
+
+		return nil
+	}
+}
+
+// WithWireLogging dumps the redacted headers and bodies of every request and response at debug
+// level. Bodies are truncated to maxBodyBytes, or DefaultWireLogMaxBodyBytes when it is zero.
+func WithWireLogging(maxBodyBytes int) ClientOpt {
+	return func(c *Client) error {
+		if maxBodyBytes < 0 {
+			return newArgError("maxBodyBytes", "it cannot be negative")
+		}
+
+		if maxBodyBytes == 0 {
+			maxBodyBytes = DefaultWireLogMaxBodyBytes
+		}
+
+		c.wireLogger = &wireLogger{maxBodyBytes: maxBodyBytes}
+
+		return nil
+	}
+}
+
+// WithTracer starts a span around every API call using the given tracer.
+func WithTracer(tracer Tracer) ClientOpt {
+	return func(c *Client) error {
+		if tracer == nil {
+			return newArgError("tracer", "cannot be nil")
+		}
+
+		c.tracer = tracer
+
+		return nil
+	}
+}
+
+// WithMetrics records the outcome and latency of every API call using the given recorder.
+func WithMetrics(metrics Metrics) ClientOpt {
+	return func(c *Client) error {
+		if metrics == nil {
+			return newArgError("metrics", "cannot be nil")
+		}
+
+		c.metrics = metrics
+
+		return nil
+	}
+}
+
+// WithMiddleware adds middleware wrapping every request. See Client.Use.
+func WithMiddleware(middleware ...Middleware) ClientOpt {
+	return func(c *Client) error {
+		for _, m := range middleware {
+			if m == nil {
+				return newArgError("middleware", "cannot be nil")
+			}
+		}
+
+		c.Use(middleware...)
+
+		return nil
+	}
+}
+
+// WithCredentialsProvider authenticates every request with the credentials returned by the
+// provider, instead of the credentials the client was created with.
+func WithCredentialsProvider(provider CredentialsProvider) ClientOpt {
+	return func(c *Client) error {
+		if provider == nil {
+			return newArgError("credentialsProvider", "cannot be nil")
+		}
+
+		c.credentialsProvider = provider
+
+		return nil
+	}
+}
@@ -0,0 +1,122 @@
+package mobilepay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestNewWithOptions_Defaults(t *testing.T) {
+	client, err := NewWithOptions("client_id", "api_key")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.mobilepay.dk/", client.BaseURL.String())
+	assert.Equal(t, DefaultTimeout, client.client.Timeout)
+	assert.Equal(t, DefaultLeveledLogger, client.Logger)
+	assert.Equal(t, userAgent, client.UserAgent)
+	assert.Equal(t, "Bearer api_key", client.headers["Authorization"])
+}
+
+func TestNewWithOptions(t *testing.T) {
+	httpClient := &http.Client{}
+	logger := &LeveledLogger{Level: LevelDebug}
+	callback := func(*http.Request, *http.Response) {}
+
+	client, err := NewWithOptions("client_id", "api_key",
+		WithBaseURL(TestBaseUrl),
+		WithHTTPClient(httpClient),
+		WithTimeout(time.Second),
+		WithLogger(logger),
+		WithUserAgent("my-app/1.0"),
+		WithHeader("x-custom", "value"),
+		WithRequestCompletedCallback(callback),
+		WithRetryPolicy(DefaultRetryPolicy()),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.sandbox.mobilepay.dk/", client.BaseURL.String())
+	assert.Equal(t, time.Second, client.client.Timeout)
+	assert.Equal(t, time.Duration(0), httpClient.Timeout)
+	assert.Equal(t, logger, client.Logger)
+	assert.Equal(t, "my-app/1.0", client.UserAgent)
+	assert.Equal(t, "value", client.headers["X-Custom"])
+	assert.NotNil(t, client.onRequestCompleted)
+	assert.Equal(t, DefaultRetryPolicy(), client.retryPolicy)
+}
+
+func TestNewWithOptions_Order(t *testing.T) {
+	httpClient := &http.Client{}
+
+	client, err := NewWithOptions("client_id", "api_key",
+		WithTimeout(time.Second),
+		WithHTTPClient(httpClient),
+		WithHeader("authorization", "Bearer other_key"),
+		WithHeader("X-IBM-CLIENT-ID", "other_id"),
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Second, client.client.Timeout)
+	assert.Equal(t, time.Duration(0), httpClient.Timeout)
+	assert.Equal(t, map[string]string{
+		"Authorization":   "Bearer other_key",
+		"X-Ibm-Client-Id": "other_id",
+	}, client.headers)
+}
+
+func TestNewWithOptions_Invalid(t *testing.T) {
+	opts := []ClientOpt{
+		WithBaseURL(""),
+		WithBaseURL(" http://foo.com"),
+		WithBaseURL("api.mobilepay.dk"),
+		WithHTTPClient(nil),
+		WithTimeout(0),
+		WithLogger(nil),
+		WithUserAgent(" "),
+		WithHeader("", "value"),
+		WithRetryPolicy(&RetryPolicy{MinBackoff: -time.Second}),
+	}
+
+	for _, opt := range opts {
+		client, err := NewWithOptions("client_id", "api_key", opt)
+		assert.Error(t, err)
+		assert.IsType(t, &ArgError{}, err)
+		assert.Nil(t, client)
+	}
+}
+
+func TestNewWithOptions_Request(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Delete("/v1/webhooks/e4a2e195-74f6-42e1-a172-83291c9d2a41").
+		MatchHeader("User-Agent", "my-app/1.0").
+		MatchHeader("x-custom", "value").
+		Reply(204)
+
+	completed := 0
+	client, err := NewWithOptions("client_id", "api_key",
+		WithBaseURL(TestBaseUrl),
+		WithUserAgent("my-app/1.0"),
+		WithHeader("x-custom", "value"),
+		WithRequestCompletedCallback(func(*http.Request, *http.Response) { completed++ }),
+	)
+	assert.Nil(t, err)
+
+	err = client.Webhook.Delete(context.TODO(), "e4a2e195-74f6-42e1-a172-83291c9d2a41")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, completed)
+}
+
+func TestNew_Invalid_URL(t *testing.T) {
+	client := New("client_id", "api_key", &Config{URL: " http://foo.com", Logger: &LeveledLogger{Level: LevelNull}})
+
+	assert.Nil(t, client.BaseURL)
+
+	req, err := client.NewRequest(context.TODO(), http.MethodGet, "v1/payments", nil)
+	assert.ErrorIs(t, err, ErrInvalidBaseURL)
+	assert.Nil(t, req)
+}
@@ -2,9 +2,11 @@ package mobilepay
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 )
 
 const paymentsBasePath = "v1/payments"
@@ -12,14 +14,17 @@ const paymentsBasePath = "v1/payments"
 type ListOptions struct {
 	PageSize   int `url:"pageSize"`
 	PageNumber int `url:"pageNumber"`
+
+	// Prefetch makes iterators fetch the next page in the background. It is not sent to the API.
+	Prefetch bool `url:"-"`
 }
 
 type RefundsListOptions struct {
 	ListOptions
-	PaymentId      string `url:"paymentId"`
-	PaymentPointId string `url:"paymentPointId,omitempty"`
-	CreatedBefore  string `url:"createdBefore,omitempty"`
-	CreatedAfter   string `url:"createdAfter,omitempty"`
+	PaymentId      string    `url:"paymentId"`
+	PaymentPointId string    `url:"paymentPointId,omitempty"`
+	CreatedBefore  time.Time `url:"createdBefore,omitempty" layout:"2006-01-02T15:04"`
+	CreatedAfter   time.Time `url:"createdAfter,omitempty" layout:"2006-01-02T15:04"`
 }
 
 type PaymentService interface {
@@ -32,25 +37,47 @@ type PaymentService interface {
 }
 
 type PaymentServiceOp struct {
-	Refund RefundService
+	Refund *RefundServiceOp
 	client *Client
 }
 
 var _ PaymentService = &PaymentServiceOp{}
 
 type Payment struct {
-	PaymentId               string `json:"paymentId,omitempty"`
-	Amount                  int    `json:"amount,omitempty"`
-	Description             string `json:"description,omitempty"`
-	PaymentPointId          string `json:"paymentPointId,omitempty"`
-	Reference               string `json:"reference,omitempty"`
-	MobilePayAppRedirectUri string `json:"mobilePayAppRedirectUri,omitempty"`
-	State                   string `json:"state,omitempty"`
-	InitiatedOn             string `json:"initiatedOn,omitempty"`
-	LastUpdatedOn           string `json:"lastUpdatedOn,omitempty"`
-	MerchantId              string `json:"merchantId,omitempty"`
-	IsoCurrencyCode         string `json:"isoCurrencyCode,omitempty"`
-	PaymentPointName        string `json:"paymentPointName,omitempty"`
+	PaymentId               string       `json:"paymentId,omitempty"`
+	Amount                  int          `json:"amount,omitempty"`
+	Description             string       `json:"description,omitempty"`
+	PaymentPointId          string       `json:"paymentPointId,omitempty"`
+	Reference               string       `json:"reference,omitempty"`
+	MobilePayAppRedirectUri string       `json:"mobilePayAppRedirectUri,omitempty"`
+	State                   PaymentState `json:"state,omitempty"`
+	InitiatedOn             Timestamp    `json:"initiatedOn"`
+	LastUpdatedOn           Timestamp    `json:"lastUpdatedOn"`
+	MerchantId              string       `json:"merchantId,omitempty"`
+	IsoCurrencyCode         string       `json:"isoCurrencyCode,omitempty"`
+	PaymentPointName        string       `json:"paymentPointName,omitempty"`
+}
+
+// MarshalJSON encodes the payment, omitting the timestamps that are not set.
+func (p Payment) MarshalJSON() ([]byte, error) {
+	// payment has the fields of Payment, but not its methods, so this does not recurse.
+	type payment Payment
+
+	encoded := struct {
+		payment
+		InitiatedOn   *Timestamp `json:"initiatedOn,omitempty"`
+		LastUpdatedOn *Timestamp `json:"lastUpdatedOn,omitempty"`
+	}{payment: payment(p)}
+
+	if !p.InitiatedOn.IsZero() {
+		encoded.InitiatedOn = &p.InitiatedOn
+	}
+
+	if !p.LastUpdatedOn.IsZero() {
+		encoded.LastUpdatedOn = &p.LastUpdatedOn
+	}
+
+	return json.Marshal(encoded)
 }
 
 type PaymentsRoot struct {
@@ -82,6 +109,8 @@ func (ps PaymentServiceOp) Get(ctx context.Context, opts ListOptions) (*Payments
 		return nil, err
 	}
 
+	ctx = withOperation(ctx, "Payment.Get", paymentsBasePath)
+
 	req, err := ps.client.NewRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
@@ -96,15 +125,39 @@ func (ps PaymentServiceOp) Get(ctx context.Context, opts ListOptions) (*Payments
 	return root, err
 }
 
+// All returns an iterator over all payments starting at opts.PageNumber.
+func (ps PaymentServiceOp) All(ctx context.Context, opts ListOptions) *PaymentIterator {
+	fetch := func(ctx context.Context, pageNumber int) (*page, error) {
+		pageOptions := opts
+		pageOptions.PageNumber = pageNumber
+
+		root, err := ps.Get(ctx, pageOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]interface{}, len(root.Payments))
+		for i, payment := range root.Payments {
+			items[i] = payment
+		}
+
+		return &page{items: items, nextPageNumber: root.NextPageNumber}, nil
+	}
+
+	return &PaymentIterator{newIter(ctx, opts.PageNumber, opts.Prefetch, fetch)}
+}
+
 func (ps *PaymentServiceOp) Find(ctx context.Context, paymentId string) (*Payment, error) {
-	if paymentId == "" {
-		ps.client.Logger.Errorf("paymentParams cannot be empty")
+	if err := validateId("paymentId", paymentId); err != nil {
+		ps.client.Logger.Errorf("invalid paymentId: %v", err)
 
-		return nil, newArgError("paymentId", "cannot be empty")
+		return nil, err
 	}
 
 	path := fmt.Sprintf("%s/%s", paymentsBasePath, paymentId)
 
+	ctx = withOperation(ctx, "Payment.Find", paymentsBasePath+"/{id}")
+
 	req, err := ps.client.NewRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
@@ -126,9 +179,28 @@ func (ps *PaymentServiceOp) Create(ctx context.Context, paymentParams *PaymentPa
 		return nil, newArgError("paymentParams", "cannot be nil")
 	}
 
+	idempotencyKey, err := ps.client.resolveIdempotencyKey(ctx, IdempotencyResourcePayment, "", paymentParams.Reference, paymentParams.IdempotencyKey)
+	if err != nil {
+		ps.client.Logger.Errorf("cannot resolve idempotency key: %v", err)
+
+		return nil, err
+	}
+
+	// the key is set on the caller's params, so it can be stored and reused after a crash.
+	paymentParams.IdempotencyKey = idempotencyKey
+	params := *paymentParams
+
+	if err := params.validate(validateUUID); err != nil {
+		ps.client.Logger.Errorf("invalid paymentParams: %v", err)
+
+		return nil, err
+	}
+
 	path := paymentsBasePath
 
-	req, err := ps.client.NewRequest(ctx, http.MethodPost, path, paymentParams)
+	ctx = withOperation(ctx, "Payment.Create", paymentsBasePath)
+
+	req, err := ps.client.NewRequest(ctx, http.MethodPost, path, &params)
 	if err != nil {
 		return nil, err
 	}
@@ -143,14 +215,16 @@ func (ps *PaymentServiceOp) Create(ctx context.Context, paymentParams *PaymentPa
 }
 
 func (ps *PaymentServiceOp) Cancel(ctx context.Context, paymentId string) error {
-	if paymentId == "" {
-		ps.client.Logger.Errorf("paymentParams cannot be empty")
+	if err := validateId("paymentId", paymentId); err != nil {
+		ps.client.Logger.Errorf("invalid paymentId: %v", err)
 
-		return newArgError("paymentId", "cannot be empty")
+		return err
 	}
 
 	path := fmt.Sprintf("%s/%s/cancel", paymentsBasePath, paymentId)
 
+	ctx = withOperation(ctx, "Payment.Cancel", paymentsBasePath+"/{id}/cancel")
+
 	req, err := ps.client.NewRequest(ctx, http.MethodPost, path, nil)
 	if err != nil {
 		return err
@@ -165,10 +239,14 @@ func (ps *PaymentServiceOp) Cancel(ctx context.Context, paymentId string) error
 }
 
 func (ps *PaymentServiceOp) Capture(ctx context.Context, paymentId string, amount int) error {
-	if paymentId == "" {
-		ps.client.Logger.Errorf("paymentId cannot be empty", paymentId)
+	v := &validator{}
+	validateUUID(v, "paymentId", paymentId)
+	validateAmount(v, amount)
 
-		return newArgError("paymentId", "cannot be empty")
+	if err := v.err(); err != nil {
+		ps.client.Logger.Errorf("invalid capture: %v", err)
+
+		return err
 	}
 
 	path := fmt.Sprintf("%s/%s/capture", paymentsBasePath, paymentId)
@@ -179,6 +257,8 @@ func (ps *PaymentServiceOp) Capture(ctx context.Context, paymentId string, amoun
 
 	requestData := &captureRequest{Amount: amount}
 
+	ctx = withOperation(ctx, "Payment.Capture", paymentsBasePath+"/{id}/capture")
+
 	req, err := ps.client.NewRequest(ctx, http.MethodPost, path, requestData)
 	if err != nil {
 		return err
@@ -191,3 +271,15 @@ func (ps *PaymentServiceOp) Capture(ctx context.Context, paymentId string, amoun
 
 	return nil
 }
+
+// CaptureMoney captures amount of a reserved payment like Capture.
+func (ps *PaymentServiceOp) CaptureMoney(ctx context.Context, paymentId string, amount Money) error {
+	minor, err := amount.int()
+	if err != nil {
+		ps.client.Logger.Errorf("invalid capture: %v", err)
+
+		return err
+	}
+
+	return ps.Capture(ctx, paymentId, minor)
+}
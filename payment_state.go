@@ -0,0 +1,120 @@
+package mobilepay
+
+import "fmt"
+
+// PaymentState is the state of a MobilePay payment.
+// See https://mobilepaydev.github.io/MobilePay-Payments-API/docs/payments-refunds/payment-states
+type PaymentState string
+
+const (
+	// PaymentStateInitiated is the state of a payment that has been created but not yet accepted by the user.
+	PaymentStateInitiated PaymentState = "initiated"
+
+	// PaymentStateReserved is the state of a payment that has been accepted by the user and can be captured.
+	PaymentStateReserved PaymentState = "reserved"
+
+	// PaymentStateCaptured is the state of a payment where the reserved amount has been captured.
+	PaymentStateCaptured PaymentState = "captured"
+
+	// PaymentStateCancelledByMerchant is the state of a payment cancelled by the merchant.
+	PaymentStateCancelledByMerchant PaymentState = "cancelledByMerchant"
+
+	// PaymentStateCancelledBySystem is the state of a payment cancelled by MobilePay,
+	// e.g. because the user did not accept it in time.
+	PaymentStateCancelledBySystem PaymentState = "cancelledBySystem"
+
+	// PaymentStateCancelledByUser is the state of a payment rejected by the user in the app.
+	PaymentStateCancelledByUser PaymentState = "cancelledByUser"
+
+	// PaymentStateExpired is the state of a reserved payment that was not captured in time.
+	PaymentStateExpired PaymentState = "expired"
+)
+
+// paymentStateTransitions lists the states a payment can move to from a given state.
+// A state without any transitions is terminal.
+var paymentStateTransitions = map[PaymentState][]PaymentState{
+	PaymentStateInitiated: {
+		PaymentStateReserved,
+		PaymentStateCancelledByMerchant,
+		PaymentStateCancelledBySystem,
+		PaymentStateCancelledByUser,
+		PaymentStateExpired,
+	},
+	PaymentStateReserved: {
+		PaymentStateCaptured,
+		PaymentStateCancelledByMerchant,
+		PaymentStateCancelledBySystem,
+		PaymentStateExpired,
+	},
+	PaymentStateCaptured:            nil,
+	PaymentStateCancelledByMerchant: nil,
+	PaymentStateCancelledBySystem:   nil,
+	PaymentStateCancelledByUser:     nil,
+	PaymentStateExpired:             nil,
+}
+
+// IsKnown reports whether s is one of the payment states documented by MobilePay.
+func (s PaymentState) IsKnown() bool {
+	_, ok := paymentStateTransitions[s]
+	return ok
+}
+
+// IsTerminal reports whether the payment can no longer change state.
+// Captured payments are terminal, refunds do not change the state of a payment.
+func (s PaymentState) IsTerminal() bool {
+	return s.IsKnown() && len(paymentStateTransitions[s]) == 0
+}
+
+// IsCancelled reports whether the payment was cancelled by the merchant, the system or the user.
+func (s PaymentState) IsCancelled() bool {
+	return s == PaymentStateCancelledByMerchant || s == PaymentStateCancelledBySystem || s == PaymentStateCancelledByUser
+}
+
+// CanCapture reports whether a payment in this state can be captured.
+func (s PaymentState) CanCapture() bool {
+	return s == PaymentStateReserved
+}
+
+// CanCancel reports whether a payment in this state can be cancelled by the merchant.
+func (s PaymentState) CanCancel() bool {
+	return s == PaymentStateInitiated || s == PaymentStateReserved
+}
+
+// CanRefund reports whether a payment in this state can be refunded.
+func (s PaymentState) CanRefund() bool {
+	return s == PaymentStateCaptured
+}
+
+// CanTransitionTo reports whether a payment can move from s to next.
+// Staying in the same state is always allowed.
+func (s PaymentState) CanTransitionTo(next PaymentState) bool {
+	if !s.IsKnown() || !next.IsKnown() {
+		return false
+	}
+
+	if s == next {
+		return true
+	}
+
+	for _, state := range paymentStateTransitions[s] {
+		if state == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateTransition returns an error wrapping ErrInvalidStateTransition if a payment
+// cannot move from s to next.
+func (s PaymentState) ValidateTransition(next PaymentState) error {
+	if !s.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %q -> %q", ErrInvalidStateTransition, s, next)
+	}
+
+	return nil
+}
+
+func (s PaymentState) String() string {
+	return string(s)
+}
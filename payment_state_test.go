@@ -0,0 +1,63 @@
+package mobilepay
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentState_Unmarshal(t *testing.T) {
+	var payment Payment
+	err := json.Unmarshal([]byte(`{"state":"cancelledByUser"}`), &payment)
+
+	assert.Nil(t, err)
+	assert.Equal(t, PaymentStateCancelledByUser, payment.State)
+	assert.True(t, payment.State.IsKnown())
+	assert.True(t, payment.State.IsCancelled())
+}
+
+func TestPaymentState_Helpers(t *testing.T) {
+	tests := []struct {
+		state      PaymentState
+		terminal   bool
+		canCapture bool
+		canCancel  bool
+		canRefund  bool
+	}{
+		{PaymentStateInitiated, false, false, true, false},
+		{PaymentStateReserved, false, true, true, false},
+		{PaymentStateCaptured, true, false, false, true},
+		{PaymentStateCancelledByMerchant, true, false, false, false},
+		{PaymentStateCancelledBySystem, true, false, false, false},
+		{PaymentStateCancelledByUser, true, false, false, false},
+		{PaymentStateExpired, true, false, false, false},
+		{PaymentState("unknown"), false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.terminal, tt.state.IsTerminal(), "IsTerminal %s", tt.state)
+		assert.Equal(t, tt.canCapture, tt.state.CanCapture(), "CanCapture %s", tt.state)
+		assert.Equal(t, tt.canCancel, tt.state.CanCancel(), "CanCancel %s", tt.state)
+		assert.Equal(t, tt.canRefund, tt.state.CanRefund(), "CanRefund %s", tt.state)
+	}
+}
+
+func TestPaymentState_Transitions(t *testing.T) {
+	assert.True(t, PaymentStateInitiated.CanTransitionTo(PaymentStateReserved))
+	assert.True(t, PaymentStateReserved.CanTransitionTo(PaymentStateCaptured))
+	assert.True(t, PaymentStateReserved.CanTransitionTo(PaymentStateExpired))
+	assert.True(t, PaymentStateReserved.CanTransitionTo(PaymentStateReserved))
+
+	assert.False(t, PaymentStateInitiated.CanTransitionTo(PaymentStateCaptured))
+	assert.False(t, PaymentStateReserved.CanTransitionTo(PaymentStateCancelledByUser))
+	assert.False(t, PaymentStateCaptured.CanTransitionTo(PaymentStateReserved))
+	assert.False(t, PaymentStateExpired.CanTransitionTo(PaymentStateCaptured))
+	assert.False(t, PaymentState("unknown").CanTransitionTo(PaymentStateReserved))
+
+	assert.Nil(t, PaymentStateInitiated.ValidateTransition(PaymentStateReserved))
+
+	err := PaymentStateCaptured.ValidateTransition(PaymentStateInitiated)
+	assert.ErrorIs(t, err, ErrInvalidStateTransition)
+	assert.Equal(t, `invalid payment state transition: "captured" -> "initiated"`, err.Error())
+}
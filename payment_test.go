@@ -146,3 +146,22 @@ func TestPayments_Capture(t *testing.T) {
 	err := client.Payment.Capture(ctx, "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", 100)
 	assert.Nil(t, err)
 }
+
+func TestPayments_CaptureMoney(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture").
+		JSON(map[string]int{"amount": 1050}).
+		Reply(204)
+
+	client := New("test", "test", config)
+	ctx := context.TODO()
+
+	amount, err := ParseMoney("10,50", DKK)
+	assert.Nil(t, err)
+
+	err = client.Payment.CaptureMoney(ctx, "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", amount)
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+}
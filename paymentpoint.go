@@ -0,0 +1,128 @@
+package mobilepay
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const paymentPointsBasePath = "v1/paymentpoints"
+
+// PaymentPointState is the state of a MobilePay payment point.
+type PaymentPointState string
+
+const (
+	// PaymentPointStatePending is the state of a payment point awaiting approval by MobilePay.
+	PaymentPointStatePending PaymentPointState = "pending"
+
+	// PaymentPointStateActive is the state of a payment point that can receive payments.
+	PaymentPointStateActive PaymentPointState = "active"
+
+	// PaymentPointStateDeactivated is the state of a payment point that can no longer receive payments.
+	PaymentPointStateDeactivated PaymentPointState = "deactivated"
+)
+
+type PaymentPointService interface {
+	List(ctx context.Context, opts *PaymentPointsListOptions) (*PaymentPointsRoot, error)
+	Find(ctx context.Context, paymentPointId string) (*PaymentPoint, error)
+}
+
+type PaymentPointServiceOp struct {
+	client *Client
+}
+
+var _ PaymentPointService = &PaymentPointServiceOp{}
+
+type PaymentPointsListOptions struct {
+	ListOptions
+	// State only lists payment points in the given state. All payment points are listed if empty.
+	State PaymentPointState `url:"state,omitempty"`
+}
+
+type PaymentPoint struct {
+	PaymentPointId   string            `json:"paymentPointId"`
+	PaymentPointName string            `json:"paymentPointName"`
+	State            PaymentPointState `json:"state"`
+}
+
+type PaymentPointsRoot struct {
+	PaymentPoints  []PaymentPoint `json:"paymentPoints"`
+	PageSize       int            `json:"pageSize"`
+	NextPageNumber int            `json:"nextPageNumber"`
+}
+
+// List payment points of the merchant.
+func (ps PaymentPointServiceOp) List(ctx context.Context, opts *PaymentPointsListOptions) (*PaymentPointsRoot, error) {
+	path, err := addOptions(paymentPointsBasePath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = withOperation(ctx, "PaymentPoint.List", paymentPointsBasePath)
+
+	req, err := ps.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	root := new(PaymentPointsRoot)
+	_, err = ps.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return root, err
+}
+
+// All returns an iterator over all payment points matching opts starting at opts.PageNumber.
+func (ps PaymentPointServiceOp) All(ctx context.Context, opts *PaymentPointsListOptions) *PaymentPointIterator {
+	listOptions := PaymentPointsListOptions{}
+	if opts != nil {
+		listOptions = *opts
+	}
+
+	fetch := func(ctx context.Context, pageNumber int) (*page, error) {
+		pageOptions := listOptions
+		pageOptions.PageNumber = pageNumber
+
+		root, err := ps.List(ctx, &pageOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]interface{}, len(root.PaymentPoints))
+		for i, paymentPoint := range root.PaymentPoints {
+			items[i] = paymentPoint
+		}
+
+		return &page{items: items, nextPageNumber: root.NextPageNumber}, nil
+	}
+
+	return &PaymentPointIterator{newIter(ctx, listOptions.PageNumber, listOptions.Prefetch, fetch)}
+}
+
+// Find a payment point by its id.
+func (ps PaymentPointServiceOp) Find(ctx context.Context, paymentPointId string) (*PaymentPoint, error) {
+	if err := validateId("paymentPointId", paymentPointId); err != nil {
+		ps.client.Logger.Errorf("invalid paymentPointId: %v", err)
+
+		return nil, err
+	}
+
+	path := fmt.Sprintf("%s/%s", paymentPointsBasePath, paymentPointId)
+
+	ctx = withOperation(ctx, "PaymentPoint.Find", paymentPointsBasePath+"/{id}")
+
+	req, err := ps.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	root := new(PaymentPoint)
+	_, err = ps.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return root, err
+}
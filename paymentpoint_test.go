@@ -0,0 +1,118 @@
+package mobilepay
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func mockPaymentPointsPage(t *testing.T, pageNumber, nextPageNumber int) {
+	testdata, err := ioutil.ReadFile("testdata/list_payment_points.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testdata = bytes.Replace(testdata, []byte("PAGE_SIZE"), []byte(strconv.Itoa(3)), 1)
+	testdata = bytes.Replace(testdata, []byte("NEXT_PAGE_NUMBER"), []byte(strconv.Itoa(nextPageNumber)), 1)
+
+	gock.New(TestBaseUrl).
+		Get("/v1/paymentpoints").
+		MatchParam("pageNumber", strconv.Itoa(pageNumber)).
+		MatchParam("pageSize", "3").
+		Reply(200).
+		JSON(testdata)
+}
+
+func TestPaymentPoints_List(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	mockPaymentPointsPage(t, 1, 2)
+
+	client := New("test", "test", config)
+
+	root, err := client.PaymentPoint.List(context.TODO(), &PaymentPointsListOptions{
+		ListOptions: ListOptions{PageSize: 3, PageNumber: 1},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, root.PaymentPoints, 3)
+	assert.Equal(t, 3, root.PageSize)
+	assert.Equal(t, 2, root.NextPageNumber)
+	assert.Equal(t, "7347ba06-95c5-4181-82e5-7c7a23609a0e", root.PaymentPoints[0].PaymentPointId)
+	assert.Equal(t, "Nullam tincidunt", root.PaymentPoints[0].PaymentPointName)
+	assert.Equal(t, PaymentPointStateActive, root.PaymentPoints[0].State)
+	assert.Equal(t, PaymentPointStatePending, root.PaymentPoints[2].State)
+	assert.True(t, gock.IsDone())
+}
+
+func TestPaymentPoints_List_State(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/paymentpoints").
+		MatchParam("state", "active").
+		Reply(200).
+		JSON(map[string]interface{}{"paymentPoints": []interface{}{}})
+
+	client := New("test", "test", config)
+
+	root, err := client.PaymentPoint.List(context.TODO(), &PaymentPointsListOptions{State: PaymentPointStateActive})
+	assert.Nil(t, err)
+	assert.Empty(t, root.PaymentPoints)
+	assert.True(t, gock.IsDone())
+}
+
+func TestPaymentPoints_All(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	mockPaymentPointsPage(t, 1, 2)
+	mockPaymentPointsPage(t, 2, 0)
+
+	client := New("test", "test", config)
+
+	it := client.PaymentPoint.All(context.TODO(), &PaymentPointsListOptions{ListOptions: ListOptions{PageSize: 3}})
+
+	count := 0
+	for it.Next() {
+		assert.NotEmpty(t, it.Current().PaymentPointId)
+		count++
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 6, count)
+	assert.True(t, gock.IsDone())
+}
+
+func TestPaymentPoints_Find(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	testdata, err := ioutil.ReadFile("testdata/get_payment_point.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gock.New(TestBaseUrl).
+		Get("/v1/paymentpoints/7347ba06-95c5-4181-82e5-7c7a23609a0e").
+		Reply(200).
+		JSON(testdata)
+
+	client := New("test", "test", config)
+
+	paymentPoint, err := client.PaymentPoint.Find(context.TODO(), "7347ba06-95c5-4181-82e5-7c7a23609a0e")
+	assert.Nil(t, err)
+	assert.Equal(t, "7347ba06-95c5-4181-82e5-7c7a23609a0e", paymentPoint.PaymentPointId)
+	assert.Equal(t, "Nullam tincidunt", paymentPoint.PaymentPointName)
+	assert.Equal(t, PaymentPointStateActive, paymentPoint.State)
+}
+
+func TestPaymentPoints_Find_Empty_Id(t *testing.T) {
+	client := New("test", "test", config)
+
+	_, err := client.PaymentPoint.Find(context.TODO(), "")
+	assert.Error(t, err)
+	assert.IsType(t, &ArgError{}, err)
+}
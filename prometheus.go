@@ -0,0 +1,199 @@
+package mobilepay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency histogram buckets
+// used by NewPrometheusMetrics when no buckets are given.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+const (
+	metricRequestsTotal   = "mobilepay_requests_total"
+	metricErrorsTotal     = "mobilepay_request_errors_total"
+	metricRequestDuration = "mobilepay_request_duration_seconds"
+
+	// unknownOperation labels calls made directly through Client.Do.
+	unknownOperation = "unknown"
+)
+
+// PrometheusMetrics is a Metrics implementation exposing the following metrics in the
+// Prometheus text format:
+//
+//	mobilepay_requests_total{operation,method}                counter
+//	mobilepay_request_errors_total{operation,status,code}     counter
+//	mobilepay_request_duration_seconds{operation}             histogram
+//
+// It implements http.Handler so it can be mounted as a scrape endpoint, e.g. on /metrics.
+type PrometheusMetrics struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	requests  map[requestLabels]uint64
+	errors    map[errorLabels]uint64
+	durations map[string]*histogram
+}
+
+type requestLabels struct {
+	operation string
+	method    string
+}
+
+type errorLabels struct {
+	operation string
+	status    string
+	code      string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+var _ Metrics = (*PrometheusMetrics)(nil)
+
+// NewPrometheusMetrics returns an empty set of metrics using the given latency buckets in
+// seconds, or DefaultLatencyBuckets when none are given.
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+
+	return &PrometheusMetrics{
+		buckets:   sorted,
+		requests:  make(map[requestLabels]uint64),
+		errors:    make(map[errorLabels]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// ObserveRequest implements Metrics.
+func (m *PrometheusMetrics) ObserveRequest(ctx context.Context, metric RequestMetric) {
+	operation := metric.Operation.Name
+	if operation == "" {
+		operation = unknownOperation
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestLabels{operation: operation, method: metric.Method}]++
+
+	if metric.Failed() {
+		status := ""
+		if metric.StatusCode != 0 {
+			status = strconv.Itoa(metric.StatusCode)
+		}
+		m.errors[errorLabels{operation: operation, status: status, code: string(metric.ErrorCode)}]++
+	}
+
+	h, ok := m.durations[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[operation] = h
+	}
+
+	seconds := metric.Duration.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+
+	m.mu.Lock()
+
+	fmt.Fprintf(&b, "# HELP %s Total number of calls to the MobilePay API.\n", metricRequestsTotal)
+	fmt.Fprintf(&b, "# TYPE %s counter\n", metricRequestsTotal)
+	requests := make([]requestLabels, 0, len(m.requests))
+	for labels := range m.requests {
+		requests = append(requests, labels)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].operation != requests[j].operation {
+			return requests[i].operation < requests[j].operation
+		}
+		return requests[i].method < requests[j].method
+	})
+	for _, labels := range requests {
+		fmt.Fprintf(&b, "%s{operation=%s,method=%s} %d\n", metricRequestsTotal,
+			quoteLabel(labels.operation), quoteLabel(labels.method), m.requests[labels])
+	}
+
+	fmt.Fprintf(&b, "# HELP %s Total number of failed calls to the MobilePay API.\n", metricErrorsTotal)
+	fmt.Fprintf(&b, "# TYPE %s counter\n", metricErrorsTotal)
+	errs := make([]errorLabels, 0, len(m.errors))
+	for labels := range m.errors {
+		errs = append(errs, labels)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].operation != errs[j].operation {
+			return errs[i].operation < errs[j].operation
+		}
+		if errs[i].status != errs[j].status {
+			return errs[i].status < errs[j].status
+		}
+		return errs[i].code < errs[j].code
+	})
+	for _, labels := range errs {
+		fmt.Fprintf(&b, "%s{operation=%s,status=%s,code=%s} %d\n", metricErrorsTotal,
+			quoteLabel(labels.operation), quoteLabel(labels.status), quoteLabel(labels.code), m.errors[labels])
+	}
+
+	fmt.Fprintf(&b, "# HELP %s Latency of calls to the MobilePay API including retries.\n", metricRequestDuration)
+	fmt.Fprintf(&b, "# TYPE %s histogram\n", metricRequestDuration)
+	operations := make([]string, 0, len(m.durations))
+	for operation := range m.durations {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		h := m.durations[operation]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&b, "%s_bucket{operation=%s,le=\"%s\"} %d\n", metricRequestDuration,
+				quoteLabel(operation), strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{operation=%s,le=\"+Inf\"} %d\n", metricRequestDuration, quoteLabel(operation), h.count)
+		fmt.Fprintf(&b, "%s_sum{operation=%s} %s\n", metricRequestDuration, quoteLabel(operation), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_count{operation=%s} %d\n", metricRequestDuration, quoteLabel(operation), h.count)
+	}
+
+	m.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+
+	return int64(n), err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// quoteLabel quotes a label value escaping backslashes, double quotes and line feeds.
+func quoteLabel(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+
+	return `"` + value + `"`
+}
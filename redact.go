@@ -0,0 +1,202 @@
+package mobilepay
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces secrets in logs and error messages.
+const redactedValue = "[REDACTED]"
+
+// minSecretLength is the minimum length of a secret value that is masked wherever it occurs.
+// Shorter values would mask unrelated parts of a message.
+const minSecretLength = 8
+
+// defaultRedactedFields are the headers, JSON fields and query parameters that are always masked.
+var defaultRedactedFields = []string{
+	"Authorization",
+	ibmClientIdHeaderKey,
+	mpSignature,
+	"signatureKey",
+	"apiKey",
+}
+
+// redactor masks secrets before they reach a log or an error message.
+type redactor struct {
+	// fields holds the lower-cased names of headers, JSON fields and query parameters to mask.
+	fields map[string]bool
+
+	mu sync.RWMutex
+	// secrets holds values, like the api key, that are masked wherever they occur.
+	secrets []string
+}
+
+func newRedactor(fields ...string) *redactor {
+	r := &redactor{fields: make(map[string]bool)}
+	r.addFields(defaultRedactedFields...)
+	r.addFields(fields...)
+
+	return r
+}
+
+func (r *redactor) addFields(fields ...string) {
+	for _, field := range fields {
+		r.fields[strings.ToLower(field)] = true
+	}
+}
+
+func (r *redactor) addSecrets(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength && !r.hasSecret(secret) {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+}
+
+func (r *redactor) hasSecret(secret string) bool {
+	for _, s := range r.secrets {
+		if s == secret {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *redactor) isRedacted(field string) bool {
+	return r.fields[strings.ToLower(field)]
+}
+
+// String masks every known secret value in s.
+func (r *redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, secret := range r.secrets {
+		s = strings.Replace(s, secret, redactedValue, -1)
+	}
+
+	return s
+}
+
+// Header returns a copy of h with the values of sensitive headers masked.
+func (r *redactor) Header(h http.Header) http.Header {
+	redacted := make(http.Header, len(h))
+	for k, values := range h {
+		if r != nil && r.isRedacted(k) {
+			redacted[k] = []string{redactedValue}
+			continue
+		}
+
+		masked := make([]string, len(values))
+		for i, v := range values {
+			masked[i] = r.String(v)
+		}
+		redacted[k] = masked
+	}
+
+	return redacted
+}
+
+// URL returns u as a string without user info and with sensitive query parameters masked.
+func (r *redactor) URL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User(redactedValue)
+	}
+
+	if r != nil && redacted.RawQuery != "" {
+		query := redacted.Query()
+		for k := range query {
+			if r.isRedacted(k) {
+				query[k] = []string{redactedValue}
+			}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+
+	return r.String(redacted.String())
+}
+
+// JSON masks the values of sensitive fields at any depth of a JSON document. Bodies that are
+// not JSON only have known secret values masked.
+func (r *redactor) JSON(body []byte) []byte {
+	if r == nil || len(body) == 0 {
+		return body
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return []byte(r.String(string(body)))
+	}
+
+	if !r.redactValue(doc) {
+		return []byte(r.String(string(body)))
+	}
+
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return []byte(redactedValue)
+	}
+
+	return []byte(r.String(string(redacted)))
+}
+
+// redactValue masks sensitive fields of v in place and reports whether anything was masked.
+func (r *redactor) redactValue(v interface{}) bool {
+	changed := false
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if r.isRedacted(k) {
+				v[k] = redactedValue
+				changed = true
+			} else if r.redactValue(value) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			if r.redactValue(value) {
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// redactFields masks known secret values in the string and error values of keysAndValues.
+func (r *redactor) redactFields(keysAndValues []interface{}) []interface{} {
+	redacted := make([]interface{}, len(keysAndValues))
+	for i, v := range keysAndValues {
+		switch v := v.(type) {
+		case string:
+			redacted[i] = r.String(v)
+		case error:
+			redacted[i] = r.String(v.Error())
+		default:
+			redacted[i] = v
+		}
+	}
+
+	return redacted
+}
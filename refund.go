@@ -21,13 +21,13 @@ type RefundParams struct {
 }
 
 type Refund struct {
-	RefundId        string `json:"refundId"`
-	PaymentId       string `json:"paymentId"`
-	Amount          int    `json:"amount"`
-	RemainingAmount int    `json:"remainingAmount,omitempty"`
-	Description     string `json:"description"`
-	Reference       string `json:"reference"`
-	CreatedOn       string `json:"createdOn"`
+	RefundId        string    `json:"refundId"`
+	PaymentId       string    `json:"paymentId"`
+	Amount          int       `json:"amount"`
+	RemainingAmount int       `json:"remainingAmount,omitempty"`
+	Description     string    `json:"description"`
+	Reference       string    `json:"reference"`
+	CreatedOn       Timestamp `json:"createdOn"`
 }
 
 type RefundsRoot struct {
@@ -49,9 +49,28 @@ func (rs *RefundServiceOp) Create(ctx context.Context, refundParams *RefundParam
 		return nil, newArgError("refundParams", "cannot be nil")
 	}
 
+	idempotencyKey, err := rs.client.resolveIdempotencyKey(ctx, IdempotencyResourceRefund, refundParams.PaymentId, refundParams.Reference, refundParams.IdempotencyKey)
+	if err != nil {
+		rs.client.Logger.Errorf("cannot resolve idempotency key: %v", err)
+
+		return nil, err
+	}
+
+	// the key is set on the caller's params, so it can be stored and reused after a crash.
+	refundParams.IdempotencyKey = idempotencyKey
+	params := *refundParams
+
+	if err := params.validate(validateUUID); err != nil {
+		rs.client.Logger.Errorf("invalid refundParams: %v", err)
+
+		return nil, err
+	}
+
 	path := refundsBasePath
 
-	req, err := rs.client.NewRequest(ctx, http.MethodPost, path, refundParams)
+	ctx = withOperation(ctx, "Refund.Create", refundsBasePath)
+
+	req, err := rs.client.NewRequest(ctx, http.MethodPost, path, &params)
 	if err != nil {
 		return nil, err
 	}
@@ -68,11 +87,21 @@ func (rs *RefundServiceOp) Create(ctx context.Context, refundParams *RefundParam
 func (rs RefundServiceOp) List(ctx context.Context, opts *RefundsListOptions) (*RefundsRoot, error) {
 	path := refundsBasePath
 
+	if opts != nil {
+		// the API expects the filters in UTC
+		utc := *opts
+		utc.CreatedBefore = opts.CreatedBefore.UTC()
+		utc.CreatedAfter = opts.CreatedAfter.UTC()
+		opts = &utc
+	}
+
 	path, err := addOptions(path, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx = withOperation(ctx, "Refund.List", refundsBasePath)
+
 	req, err := rs.client.NewRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
@@ -86,3 +115,30 @@ func (rs RefundServiceOp) List(ctx context.Context, opts *RefundsListOptions) (*
 
 	return root, err
 }
+
+// All returns an iterator over all refunds matching opts starting at opts.PageNumber.
+func (rs RefundServiceOp) All(ctx context.Context, opts *RefundsListOptions) *RefundIterator {
+	listOptions := RefundsListOptions{}
+	if opts != nil {
+		listOptions = *opts
+	}
+
+	fetch := func(ctx context.Context, pageNumber int) (*page, error) {
+		pageOptions := listOptions
+		pageOptions.PageNumber = pageNumber
+
+		root, err := rs.List(ctx, &pageOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]interface{}, len(root.Refunds))
+		for i, refund := range root.Refunds {
+			items[i] = refund
+		}
+
+		return &page{items: items, nextPageNumber: root.NextPageNumber}, nil
+	}
+
+	return &RefundIterator{newIter(ctx, listOptions.PageNumber, listOptions.Prefetch, fetch)}
+}
@@ -0,0 +1,169 @@
+package mobilepay
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Merchant holds the credentials used to call MobilePay on behalf of a merchant.
+type Merchant struct {
+	// MerchantId identifies the merchant in the registry.
+	MerchantId string
+
+	// IbmClientId and ApiKey are the credentials of the merchant.
+	IbmClientId string
+	ApiKey      string
+
+	// PaymentPointIds are the payment points owned by the merchant.
+	PaymentPointIds []string
+}
+
+// MerchantRegistry hands out a Client per merchant for platforms serving many merchants.
+//
+// Clients are created on first use and cached. They share one http.Client, and so one
+// connection pool. Registering a merchant again rotates its credentials: clients handed out
+// before keep the old credentials, so fetch the client from the registry for every unit of work.
+type MerchantRegistry struct {
+	httpClient *http.Client
+	opts       []ClientOpt
+
+	mu            sync.RWMutex
+	merchants     map[string]Merchant
+	paymentPoints map[string]string
+	clients       map[string]*Client
+}
+
+// NewMerchantRegistry returns an empty registry. The options are applied to every client it creates.
+// Clients share a default http.Client unless one is set with WithHTTPClient.
+func NewMerchantRegistry(opts ...ClientOpt) *MerchantRegistry {
+	return &MerchantRegistry{
+		httpClient:    newDefaultHTTPClient(),
+		opts:          opts,
+		merchants:     make(map[string]Merchant),
+		paymentPoints: make(map[string]string),
+		clients:       make(map[string]*Client),
+	}
+}
+
+// Register adds a merchant to the registry or replaces the credentials and payment points of a
+// merchant already registered.
+func (r *MerchantRegistry) Register(merchant Merchant) error {
+	if merchant.MerchantId == "" {
+		return newArgError("merchantId", "cannot be empty")
+	}
+
+	if merchant.IbmClientId == "" {
+		return newArgError("ibmClientId", "cannot be empty")
+	}
+
+	if merchant.ApiKey == "" {
+		return newArgError("apiKey", "cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, paymentPointId := range merchant.PaymentPointIds {
+		if owner, ok := r.paymentPoints[paymentPointId]; ok && owner != merchant.MerchantId {
+			return newArgError("paymentPointIds", fmt.Sprintf("payment point %s is registered to merchant %s", paymentPointId, owner))
+		}
+	}
+
+	r.remove(merchant.MerchantId)
+
+	merchant.PaymentPointIds = append([]string(nil), merchant.PaymentPointIds...)
+	r.merchants[merchant.MerchantId] = merchant
+	for _, paymentPointId := range merchant.PaymentPointIds {
+		r.paymentPoints[paymentPointId] = merchant.MerchantId
+	}
+
+	return nil
+}
+
+// Remove removes a merchant and its payment points from the registry.
+func (r *MerchantRegistry) Remove(merchantId string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remove(merchantId)
+}
+
+func (r *MerchantRegistry) remove(merchantId string) {
+	merchant, ok := r.merchants[merchantId]
+	if !ok {
+		return
+	}
+
+	for _, paymentPointId := range merchant.PaymentPointIds {
+		delete(r.paymentPoints, paymentPointId)
+	}
+	delete(r.merchants, merchantId)
+	delete(r.clients, merchantId)
+}
+
+// Client returns the client of the given merchant, creating it if needed.
+// It returns an error wrapping ErrUnknownMerchant if the merchant is not registered.
+func (r *MerchantRegistry) Client(merchantId string) (*Client, error) {
+	r.mu.RLock()
+	c, ok := r.clients[merchantId]
+	r.mu.RUnlock()
+	if ok {
+		return c, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.client(merchantId)
+}
+
+// ClientForPaymentPoint returns the client of the merchant owning the given payment point.
+// It returns an error wrapping ErrUnknownMerchant if no merchant owns the payment point.
+func (r *MerchantRegistry) ClientForPaymentPoint(paymentPointId string) (*Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	merchantId, ok := r.paymentPoints[paymentPointId]
+	if !ok {
+		return nil, fmt.Errorf("%w: no merchant owns payment point %s", ErrUnknownMerchant, paymentPointId)
+	}
+
+	return r.client(merchantId)
+}
+
+// client returns the cached client of the merchant or creates it. r.mu must be held for writing.
+func (r *MerchantRegistry) client(merchantId string) (*Client, error) {
+	if c, ok := r.clients[merchantId]; ok {
+		return c, nil
+	}
+
+	merchant, ok := r.merchants[merchantId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMerchant, merchantId)
+	}
+
+	opts := append([]ClientOpt{WithHTTPClient(r.httpClient)}, r.opts...)
+	c, err := NewWithOptions(merchant.IbmClientId, merchant.ApiKey, opts...)
+	if err != nil {
+		return nil, err
+	}
+	r.clients[merchantId] = c
+
+	return c, nil
+}
+
+// Merchants returns the ids of the registered merchants in sorted order.
+func (r *MerchantRegistry) Merchants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.merchants))
+	for id := range r.merchants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
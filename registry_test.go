@@ -0,0 +1,146 @@
+package mobilepay
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func newTestRegistry(t *testing.T) *MerchantRegistry {
+	registry := NewMerchantRegistry(WithBaseURL(TestBaseUrl))
+
+	assert.Nil(t, registry.Register(Merchant{
+		MerchantId:      "merchant-1",
+		IbmClientId:     "client-1",
+		ApiKey:          "key-1",
+		PaymentPointIds: []string{"1f8ed17f-f310-4f40-a7a4-df78185efbdd"},
+	}))
+	assert.Nil(t, registry.Register(Merchant{
+		MerchantId:  "merchant-2",
+		IbmClientId: "client-2",
+		ApiKey:      "key-2",
+	}))
+
+	return registry
+}
+
+func TestMerchantRegistry_Client(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	c1, err := registry.Client("merchant-1")
+	assert.Nil(t, err)
+	assert.Equal(t, TestBaseUrl+"/", c1.BaseURL.String())
+
+	cached, err := registry.Client("merchant-1")
+	assert.Nil(t, err)
+	assert.Same(t, c1, cached)
+
+	c2, err := registry.Client("merchant-2")
+	assert.Nil(t, err)
+	assert.NotSame(t, c1, c2)
+	assert.Same(t, c1.client, c2.client, "clients should share one http.Client")
+
+	byPaymentPoint, err := registry.ClientForPaymentPoint("1f8ed17f-f310-4f40-a7a4-df78185efbdd")
+	assert.Nil(t, err)
+	assert.Same(t, c1, byPaymentPoint)
+
+	assert.Equal(t, []string{"merchant-1", "merchant-2"}, registry.Merchants())
+}
+
+func TestMerchantRegistry_Unknown_Merchant(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	_, err := registry.Client("merchant-3")
+	assert.ErrorIs(t, err, ErrUnknownMerchant)
+
+	_, err = registry.ClientForPaymentPoint("2f8ed17f-f310-4f40-a7a4-df78185efbdd")
+	assert.ErrorIs(t, err, ErrUnknownMerchant)
+}
+
+func TestMerchantRegistry_Rotate_Credentials(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		MatchHeader("Authorization", "Bearer key-1-rotated").
+		MatchHeader("x-ibm-client-id", "client-1").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	registry := newTestRegistry(t)
+
+	old, err := registry.Client("merchant-1")
+	assert.Nil(t, err)
+
+	assert.Nil(t, registry.Register(Merchant{
+		MerchantId:      "merchant-1",
+		IbmClientId:     "client-1",
+		ApiKey:          "key-1-rotated",
+		PaymentPointIds: []string{"3f8ed17f-f310-4f40-a7a4-df78185efbdd"},
+	}))
+
+	rotated, err := registry.ClientForPaymentPoint("3f8ed17f-f310-4f40-a7a4-df78185efbdd")
+	assert.Nil(t, err)
+	assert.NotSame(t, old, rotated)
+
+	_, err = rotated.Webhook.Get(context.TODO())
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+
+	_, err = registry.ClientForPaymentPoint("1f8ed17f-f310-4f40-a7a4-df78185efbdd")
+	assert.ErrorIs(t, err, ErrUnknownMerchant, "payment points of the old registration should be removed")
+}
+
+func TestMerchantRegistry_Remove(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	_, err := registry.Client("merchant-1")
+	assert.Nil(t, err)
+
+	registry.Remove("merchant-1")
+
+	_, err = registry.Client("merchant-1")
+	assert.ErrorIs(t, err, ErrUnknownMerchant)
+
+	_, err = registry.ClientForPaymentPoint("1f8ed17f-f310-4f40-a7a4-df78185efbdd")
+	assert.ErrorIs(t, err, ErrUnknownMerchant)
+	assert.Equal(t, []string{"merchant-2"}, registry.Merchants())
+}
+
+func TestMerchantRegistry_Register_Invalid(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	assert.Error(t, registry.Register(Merchant{IbmClientId: "client", ApiKey: "key"}))
+	assert.Error(t, registry.Register(Merchant{MerchantId: "merchant-3", ApiKey: "key"}))
+	assert.Error(t, registry.Register(Merchant{MerchantId: "merchant-3", IbmClientId: "client"}))
+
+	err := registry.Register(Merchant{
+		MerchantId:      "merchant-3",
+		IbmClientId:     "client-3",
+		ApiKey:          "key-3",
+		PaymentPointIds: []string{"1f8ed17f-f310-4f40-a7a4-df78185efbdd"},
+	})
+	assert.Error(t, err, "a payment point cannot belong to two merchants")
+}
+
+func TestMerchantRegistry_Concurrent_Access(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	var wg sync.WaitGroup
+	clients := make([]*Client, 20)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = registry.Client("merchant-2")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, c := range clients {
+		assert.Same(t, clients[0], c)
+	}
+}
@@ -0,0 +1,94 @@
+package mobilepay
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestOption configures a single call to the MobilePay API. Request options are attached to
+// the context passed to a service method with WithRequestOptions.
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the per-call settings honoured by NewRequest and Do.
+type requestOptions struct {
+	header         http.Header
+	timeout        time.Duration
+	idempotencyKey string
+	requestID      string
+}
+
+type requestOptionsContextKey struct{}
+
+// WithRequestOptions returns a copy of ctx carrying the given request options. Options already
+// attached to ctx are kept unless overridden.
+//
+//	ctx = mobilepay.WithRequestOptions(ctx,
+//		mobilepay.WithRequestTimeout(5*time.Second),
+//		mobilepay.WithRequestID("order-1234"),
+//	)
+//	err := mp.Payment.Capture(ctx, paymentId, 100)
+func WithRequestOptions(ctx context.Context, opts ...RequestOption) context.Context {
+	options := &requestOptions{header: make(http.Header)}
+	if parent := requestOptionsFromContext(ctx); parent != nil {
+		*options = *parent
+		options.header = parent.header.Clone()
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return context.WithValue(ctx, requestOptionsContextKey{}, options)
+}
+
+// requestOptionsFromContext returns the request options attached to ctx, or nil.
+func requestOptionsFromContext(ctx context.Context) *requestOptions {
+	options, _ := ctx.Value(requestOptionsContextKey{}).(*requestOptions)
+	return options
+}
+
+// WithRequestHeader sets an HTTP header on the request.
+func WithRequestHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithRequestTimeout limits the time spent on the call including retries. It overrides the
+// timeout of the HTTP client only if it is shorter.
+func WithRequestTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithIdempotencyKey sets the idempotency key used when creating a payment or a refund whose
+// params don't have one.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithRequestID sends the given id as the MobilePay CorrelationId header, so the call can be
+// found in the MobilePay logs.
+func WithRequestID(id string) RequestOption {
+	return func(o *requestOptions) {
+		o.requestID = id
+	}
+}
+
+// apply sets the headers of the request options on req.
+func (o *requestOptions) apply(req *http.Request) {
+	for k, values := range o.header {
+		req.Header.Del(k)
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	if o.requestID != "" {
+		req.Header.Set(correlationIdHeader, o.requestID)
+	}
+}
@@ -0,0 +1,103 @@
+package mobilepay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestRequestOptions_Header_And_Request_ID(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture").
+		MatchHeader("X-Tenant", "acme").
+		MatchHeader("CorrelationId", "order-1234").
+		Reply(204)
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture").
+		Reply(204)
+
+	client := New("test", "test", &Config{URL: TestBaseUrl})
+
+	ctx := WithRequestOptions(context.TODO(), WithRequestHeader("X-Tenant", "acme"))
+	ctx = WithRequestOptions(ctx, WithRequestID("order-1234"))
+
+	err := client.Payment.Capture(ctx, "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", 100)
+	assert.Nil(t, err)
+	assert.True(t, gock.IsPending(), "the options must only apply to the call they are given to")
+
+	err = client.Payment.Capture(context.TODO(), "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", 100)
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+}
+
+func TestRequestOptions_Header_Overrides_Client_Header(t *testing.T) {
+	client := New("test", "test", &Config{URL: TestBaseUrl})
+
+	ctx := WithRequestOptions(context.TODO(), WithRequestHeader("User-Agent", "custom"))
+	req, err := client.NewRequest(ctx, http.MethodGet, "v1/webhooks", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"custom"}, req.Header.Values("User-Agent"))
+}
+
+func TestRequestOptions_Nested_Contexts_Do_Not_Leak(t *testing.T) {
+	parent := WithRequestOptions(context.TODO(), WithRequestHeader("X-Tenant", "acme"))
+	child := WithRequestOptions(parent, WithRequestHeader("X-Tenant", "other"))
+
+	assert.Equal(t, "acme", requestOptionsFromContext(parent).header.Get("X-Tenant"))
+	assert.Equal(t, "other", requestOptionsFromContext(child).header.Get("X-Tenant"))
+}
+
+func TestRequestOptions_Timeout(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		Delay(time.Second).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	client := New("test", "test", &Config{URL: TestBaseUrl})
+
+	ctx := WithRequestOptions(context.TODO(), WithRequestTimeout(10*time.Millisecond))
+	start := time.Now()
+	_, err := client.Webhook.Get(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestRequestOptions_Idempotency_Key(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	key := "7576910d-9789-4fef-a72e-877d89afec94"
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		BodyString(`"idempotencyKey":"` + key + `"`).
+		Reply(200).
+		JSON(map[string]interface{}{"amount": 100})
+
+	client := New("test", "test", &Config{URL: TestBaseUrl})
+
+	ctx := WithRequestOptions(context.TODO(), WithIdempotencyKey(key))
+	refund, err := client.Payment.Refund.Create(ctx, &RefundParams{
+		PaymentId: "211444eb-1c4e-4194-a58f-905d97877cc5",
+		Amount:    100,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 100, refund.Amount)
+	assert.True(t, gock.IsDone())
+
+	// a key in the params takes precedence
+	resolved, err := client.resolveIdempotencyKey(ctx, IdempotencyResourceRefund, "", "", "8576910d-9789-4fef-a72e-877d89afec94")
+	assert.Nil(t, err)
+	assert.Equal(t, "8576910d-9789-4fef-a72e-877d89afec94", resolved)
+
+	_, err = client.Payment.Refund.Create(WithRequestOptions(context.TODO(), WithIdempotencyKey("not-a-uuid")), &RefundParams{Amount: 100})
+	assert.Error(t, err)
+}
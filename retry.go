@@ -0,0 +1,255 @@
+package mobilepay
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how Client.Do retries requests that failed because of
+// a network error or timeout, rate limiting (429) or a server error (5xx). Permanent
+// transport errors, like an unsupported scheme or an invalid certificate, are not retried.
+//
+// Requests using a non-idempotent method (POST) are only retried when they carry
+// an idempotency key, as MobilePay then guarantees the operation is only applied once.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// MinBackoff is the base delay of the exponential backoff.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts, including a delay requested by
+	// MobilePay with a Retry-After header. Zero means no limit.
+	MaxBackoff time.Duration
+
+	// MaxElapsedTime caps the total time spent on a request including all of its retries.
+	// It is enforced with a context deadline, so it also ends an attempt still in flight.
+	// Zero means no limit.
+	MaxElapsedTime time.Duration
+}
+
+// DefaultRetryPolicy returns a sensible retry policy that can be set on Config.RetryPolicy.
+// Every call returns a new policy, so changing it does not affect other clients.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		MinBackoff:     500 * time.Millisecond,
+		MaxBackoff:     8 * time.Second,
+		MaxElapsedTime: 30 * time.Second,
+	}
+}
+
+// idempotencyKeyContextKey is the context key used to mark a request as safe to retry.
+type idempotencyKeyContextKey struct{}
+
+// idempotentBody is implemented by request bodies that carry a MobilePay idempotency key.
+type idempotentBody interface {
+	idempotencyKey() string
+}
+
+func (p *PaymentParams) idempotencyKey() string {
+	return p.IdempotencyKey
+}
+
+func (p *RefundParams) idempotencyKey() string {
+	return p.IdempotencyKey
+}
+
+// markIdempotent marks req as safe to retry using the given idempotency key.
+func markIdempotent(req *http.Request, key string) *http.Request {
+	if key == "" {
+		return req
+	}
+
+	return req.WithContext(context.WithValue(req.Context(), idempotencyKeyContextKey{}, key))
+}
+
+func (c *Client) doWithRetries(ctx context.Context, req *http.Request) (*http.Response, error) {
+	policy := c.retryPolicy
+	if policy == nil || policy.MaxAttempts <= 1 || !isRetryableRequest(req) {
+		return c.send(ctx, req, 1)
+	}
+
+	cancel := func() {}
+	if policy.MaxElapsedTime > 0 {
+		ctx, cancel = context.WithTimeout(ctx, policy.MaxElapsedTime)
+	}
+
+	resp, err := c.retry(ctx, req, policy)
+	if resp == nil {
+		cancel()
+		return resp, err
+	}
+
+	// the deadline must outlive the call, as the body of the response is read afterwards.
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, err
+}
+
+func (c *Client) retry(ctx context.Context, req *http.Request, policy *RetryPolicy) (*http.Response, error) {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.send(ctx, req, attempt)
+		if attempt >= policy.MaxAttempts || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		wait := policy.backoff(attempt, resp)
+		if policy.MaxElapsedTime > 0 && time.Since(start)+wait > policy.MaxElapsedTime {
+			return resp, err
+		}
+
+		c.log(ctx, LevelWarn, "Retrying MobilePay request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"attempt", attempt,
+			"max_attempts", policy.MaxAttempts,
+			"wait", wait,
+		)
+
+		if resp != nil {
+			drainBody(resp)
+		}
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isRetryableRequest reports whether req can safely be sent more than once.
+func isRetryableRequest(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		key, _ := req.Context().Value(idempotencyKeyContextKey{}).(string)
+		return key != ""
+	}
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return isTransientError(err)
+	}
+
+	return isRetryableStatus(resp.StatusCode)
+}
+
+// backoff returns the delay before the next attempt. A Retry-After header sent by
+// MobilePay takes precedence over the exponential backoff, but is capped by MaxBackoff too.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+				wait = p.MaxBackoff
+			}
+			return wait
+		}
+	}
+
+	wait := p.MinBackoff << uint(attempt-1)
+	if wait <= 0 || (p.MaxBackoff > 0 && wait > p.MaxBackoff) {
+		wait = p.MaxBackoff
+	}
+
+	if wait <= 0 {
+		return 0
+	}
+
+	// equal jitter: keep half of the delay and randomize the other half.
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// rewindBody resets the body of req so it can be sent again.
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+
+	return nil
+}
+
+// cancelOnClose cancels the context of a request once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
+
+// drainBody reads and closes the body of a response that is about to be discarded,
+// so that the underlying TCP connection can be reused.
+func drainBody(resp *http.Response) {
+	const maxBodySlurpSize = 2 << 10
+	_, _ = io.CopyN(ioutil.Discard, resp.Body, maxBodySlurpSize)
+	_ = resp.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
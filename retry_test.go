@@ -0,0 +1,276 @@
+package mobilepay
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func newRetryClient() *Client {
+	return New("test", "test", &Config{
+		URL: TestBaseUrl,
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts: 3,
+			MinBackoff:  time.Millisecond,
+			MaxBackoff:  5 * time.Millisecond,
+		},
+	})
+}
+
+func TestRetry_Server_Error(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/payments/186d2b31-ff25-4414-9fd1-bfe9807fa8b7").
+		Reply(503)
+
+	gock.New(TestBaseUrl).
+		Get("/v1/payments/186d2b31-ff25-4414-9fd1-bfe9807fa8b7").
+		Reply(200).
+		JSON(map[string]string{"paymentId": "186d2b31-ff25-4414-9fd1-bfe9807fa8b7"})
+
+	client := newRetryClient()
+
+	payment, err := client.Payment.Find(context.TODO(), "186d2b31-ff25-4414-9fd1-bfe9807fa8b7")
+	assert.Nil(t, err)
+	assert.Equal(t, "186d2b31-ff25-4414-9fd1-bfe9807fa8b7", payment.PaymentId)
+	assert.True(t, gock.IsDone())
+}
+
+func TestRetry_Network_Error(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Delete("/v1/webhooks/e4a2e195-74f6-42e1-a172-83291c9d2a41").
+		ReplyError(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")})
+
+	gock.New(TestBaseUrl).
+		Delete("/v1/webhooks/e4a2e195-74f6-42e1-a172-83291c9d2a41").
+		Reply(204)
+
+	client := newRetryClient()
+
+	err := client.Webhook.Delete(context.TODO(), "e4a2e195-74f6-42e1-a172-83291c9d2a41")
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+}
+
+func TestRetry_Permanent_Transport_Error(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		ReplyError(x509.UnknownAuthorityError{})
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	client := newRetryClient()
+
+	_, err := client.Webhook.Get(context.TODO())
+	assert.Error(t, err)
+	assert.True(t, gock.IsPending())
+}
+
+func TestRetry_shouldRetry(t *testing.T) {
+	ctx := context.TODO()
+
+	assert.True(t, shouldRetry(ctx, nil, &url.Error{Op: "Get", URL: TestBaseUrl, Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}))
+	assert.False(t, shouldRetry(ctx, nil, &url.Error{Op: "Get", URL: TestBaseUrl, Err: errors.New(`unsupported protocol scheme "ftp"`)}))
+	assert.False(t, shouldRetry(ctx, nil, &url.Error{Op: "Get", URL: TestBaseUrl, Err: x509.UnknownAuthorityError{}}))
+	assert.True(t, shouldRetry(ctx, &http.Response{StatusCode: http.StatusBadGateway}, nil))
+	assert.False(t, shouldRetry(ctx, &http.Response{StatusCode: http.StatusBadRequest}, nil))
+}
+
+func TestRetry_Max_Attempts(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Times(3).
+		Reply(500).
+		JSON("Backend error")
+
+	client := newRetryClient()
+
+	_, err := client.Webhook.Get(context.TODO())
+	assert.Error(t, err)
+	mpError, ok := err.(*ErrorResponse)
+	assert.True(t, ok)
+	assert.Equal(t, 500, mpError.StatusCode)
+	assert.True(t, gock.IsDone())
+}
+
+func TestRetry_Max_Elapsed_Time(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(429).
+		SetHeader("Retry-After", "60")
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	client := newRetryClient()
+	client.retryPolicy.MaxBackoff = time.Minute
+	client.retryPolicy.MaxElapsedTime = time.Second
+
+	_, err := client.Webhook.Get(context.TODO())
+	assert.Error(t, err)
+	assert.True(t, gock.IsPending())
+}
+
+func TestRetry_Max_Elapsed_Time_In_Flight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := New("test", "test", &Config{
+		URL:        server.URL,
+		HTTPClient: server.Client(),
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts:    3,
+			MaxElapsedTime: 50 * time.Millisecond,
+		},
+	})
+
+	start := time.Now()
+	_, err := client.Webhook.Get(context.TODO())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestRetry_Rate_Limited_Retry_After(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(429).
+		SetHeader("Retry-After", "0")
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	client := newRetryClient()
+	client.retryPolicy.MinBackoff = time.Minute
+	client.retryPolicy.MaxBackoff = time.Minute
+
+	_, err := client.Webhook.Get(context.TODO())
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+}
+
+func TestRetry_Post_Without_Idempotency_Key(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture").
+		Reply(503)
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture").
+		Reply(204)
+
+	client := newRetryClient()
+
+	err := client.Payment.Capture(context.TODO(), "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", 100)
+	assert.Error(t, err)
+	assert.True(t, gock.IsPending())
+}
+
+func TestRetry_Post_With_Idempotency_Key_Rewinds_Body(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	params := &RefundParams{
+		Amount:         100,
+		IdempotencyKey: "7576910d-9789-4fef-a72e-877d89afec94",
+		PaymentId:      "211444eb-1c4e-4194-a58f-905d97877cc5",
+		Reference:      "test",
+		Description:    "this is a test payment",
+	}
+
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		JSON(params).
+		Reply(502)
+
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		JSON(params).
+		Reply(200).
+		JSON(map[string]interface{}{"paymentId": params.PaymentId, "amount": 100})
+
+	client := newRetryClient()
+
+	refund, err := client.Payment.Refund.Create(context.TODO(), params)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, refund.Amount)
+	assert.True(t, gock.IsDone())
+}
+
+func TestRetry_Context_Cancelled(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(503)
+
+	client := newRetryClient()
+	client.retryPolicy.MinBackoff = time.Minute
+	client.retryPolicy.MaxBackoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Webhook.Get(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRetry_parseRetryAfter(t *testing.T) {
+	wait, ok := parseRetryAfter("2")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, wait)
+
+	wait, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestRetry_backoff(t *testing.T) {
+	policy := &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for attempt := 1; attempt < 10; attempt++ {
+		wait := policy.backoff(attempt, nil)
+		assert.True(t, wait >= 50*time.Millisecond)
+		assert.True(t, wait <= time.Second)
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+	assert.Equal(t, time.Second, policy.backoff(1, resp))
+
+	policy.MaxBackoff = 0
+	assert.Equal(t, time.Hour, policy.backoff(1, resp))
+}
@@ -0,0 +1,71 @@
+//go:build go1.21
+// +build go1.21
+
+package mobilepay
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SlogLogger adapts a *slog.Logger so it can be used as Client.Logger.
+// Requests are logged with their method, path, status, latency, attempt,
+// correlation id and payment id as slog attributes.
+type SlogLogger struct {
+	Logger *slog.Logger
+}
+
+var _ LeveledLoggerInterface = &SlogLogger{}
+var _ StructuredLogger = &SlogLogger{}
+
+// NewSlogLogger returns a SlogLogger backed by logger, or by slog.Default() if logger is nil.
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SlogLogger{Logger: logger}
+}
+
+// Log logs msg with the given key/value pairs as attributes.
+func (l *SlogLogger) Log(ctx context.Context, level Level, msg string, keysAndValues ...interface{}) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	l.Logger.Log(ctx, slogLevel(level), msg, keysAndValues...)
+}
+
+// Debugf logs a debug message using Printf conventions.
+func (l *SlogLogger) Debugf(format string, v ...interface{}) {
+	l.Logger.Debug(fmt.Sprintf(format, v...))
+}
+
+// Errorf logs an error message using Printf conventions.
+func (l *SlogLogger) Errorf(format string, v ...interface{}) {
+	l.Logger.Error(fmt.Sprintf(format, v...))
+}
+
+// Infof logs an informational message using Printf conventions.
+func (l *SlogLogger) Infof(format string, v ...interface{}) {
+	l.Logger.Info(fmt.Sprintf(format, v...))
+}
+
+// Warnf logs a warning message using Printf conventions.
+func (l *SlogLogger) Warnf(format string, v ...interface{}) {
+	l.Logger.Warn(fmt.Sprintf(format, v...))
+}
+
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case LevelError:
+		return slog.LevelError
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelInfo:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
@@ -0,0 +1,52 @@
+//go:build go1.21
+// +build go1.21
+
+package mobilepay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestSlogLogger_Request_Fields(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture").
+		Reply(409).
+		JSON(map[string]string{"code": "amount_too_large", "correlationId": "d503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a"})
+
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	client := New("test", "test", &Config{URL: TestBaseUrl, Logger: logger})
+
+	err := client.Payment.Capture(context.TODO(), "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", 100)
+	assert.ErrorIs(t, err, ErrorCodeAmountTooLarge)
+
+	var record map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "MobilePay request completed", record["msg"])
+	assert.Equal(t, "POST", record["method"])
+	assert.Equal(t, "/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture", record["path"])
+	assert.Equal(t, float64(409), record["status"])
+	assert.Equal(t, float64(1), record["attempt"])
+	assert.Contains(t, record, "latency")
+	assert.Equal(t, "d503b7ed-b5d0-4751-b3ac-52ecd7cd3a4a", record["correlation_id"])
+	assert.Equal(t, "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", record["payment_id"])
+}
+
+func TestSlogLogger_Printf(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	logger.Errorf("paymentId %s cannot be empty", "x")
+	assert.Contains(t, buf.String(), `level=ERROR msg="paymentId x cannot be empty"`)
+}
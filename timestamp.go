@@ -0,0 +1,73 @@
+package mobilepay
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RefundFilterTimeFormat is the format of the createdBefore and createdAfter filters of the
+// refunds endpoint. Filters are sent in UTC.
+const RefundFilterTimeFormat = "2006-01-02T15:04"
+
+// timestampFormats are the formats MobilePay uses for timestamps. RFC 3339 covers both the Z and
+// the +00:00 offsets as well as fractional seconds. Timestamps without an offset are in UTC.
+var timestampFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	RefundFilterTimeFormat,
+}
+
+// Timestamp is a point in time returned by the MobilePay API. It parses every format used by
+// MobilePay and marshals to RFC 3339.
+type Timestamp struct {
+	time.Time
+}
+
+// ParseTimestamp parses a timestamp in any of the formats used by MobilePay.
+func ParseTimestamp(value string) (Timestamp, error) {
+	for _, layout := range timestampFormats {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return Timestamp{Time: t}, nil
+		}
+	}
+
+	return Timestamp{}, fmt.Errorf("mobilepay: cannot parse %q as a timestamp", value)
+}
+
+// MarshalJSON encodes the timestamp in RFC 3339, or null for the zero time.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(t.Time.Format(time.RFC3339Nano))
+}
+
+// UnmarshalJSON decodes a timestamp in any of the formats used by MobilePay. null and the empty
+// string decode to the zero time.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = Timestamp{}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	if value == "" {
+		*t = Timestamp{}
+		return nil
+	}
+
+	parsed, err := ParseTimestamp(value)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+
+	return nil
+}
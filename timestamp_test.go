@@ -0,0 +1,120 @@
+package mobilepay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	expected := time.Date(2021, 7, 19, 12, 42, 38, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"2021-07-19T12:42:38Z":              expected,
+		"2021-07-19T12:42:38+00:00":         expected,
+		"2021-07-19T14:42:38+02:00":         expected,
+		"2021-07-19T12:42:38.123Z":          expected.Add(123 * time.Millisecond),
+		"2021-07-19T12:42:38.1234567+00:00": expected.Add(123456700 * time.Nanosecond),
+		"2021-07-19T12:42:38":               expected,
+		"2021-07-19T12:42:38.5":             expected.Add(500 * time.Millisecond),
+		"2021-07-19T12:42Z":                 expected.Truncate(time.Minute),
+		"2021-07-19T12:42+00:00":            expected.Truncate(time.Minute),
+		"2021-07-19T12:42":                  expected.Truncate(time.Minute),
+	}
+
+	for value, want := range tests {
+		ts, err := ParseTimestamp(value)
+		assert.Nil(t, err, value)
+		assert.True(t, want.Equal(ts.Time), "%s: got %s", value, ts.Time)
+	}
+
+	for _, value := range []string{"", "2021-07-19", "19-07-2021 12:42", "yesterday"} {
+		_, err := ParseTimestamp(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestTimestamp_JSON(t *testing.T) {
+	var payment struct {
+		InitiatedOn   Timestamp `json:"initiatedOn"`
+		LastUpdatedOn Timestamp `json:"lastUpdatedOn"`
+		CreatedOn     Timestamp `json:"createdOn"`
+	}
+
+	err := json.Unmarshal([]byte(`{"initiatedOn":"2021-08-20T05:18:07Z","lastUpdatedOn":"2021-08-20T05:18:07+00:00","createdOn":null}`), &payment)
+	assert.Nil(t, err)
+	assert.True(t, payment.InitiatedOn.Equal(payment.LastUpdatedOn.Time))
+	assert.True(t, payment.CreatedOn.IsZero())
+
+	data, err := json.Marshal(payment)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"initiatedOn":"2021-08-20T05:18:07Z","lastUpdatedOn":"2021-08-20T05:18:07Z","createdOn":null}`, string(data))
+
+	var ts Timestamp
+	assert.Nil(t, json.Unmarshal([]byte(`""`), &ts))
+	assert.True(t, ts.IsZero())
+	assert.Error(t, json.Unmarshal([]byte(`"not a time"`), &ts))
+	assert.Error(t, json.Unmarshal([]byte(`1629436687`), &ts))
+}
+
+func TestPayment_Unset_Timestamps_JSON(t *testing.T) {
+	data, err := json.Marshal(Payment{PaymentId: "x"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"paymentId":"x"}`, string(data))
+
+	initiatedOn := Timestamp{time.Date(2022, 2, 20, 16, 35, 28, 0, time.UTC)}
+	data, err = json.Marshal(Payment{PaymentId: "x", InitiatedOn: initiatedOn})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"paymentId":"x","initiatedOn":"2022-02-20T16:35:28Z"}`, string(data))
+
+	var decoded Payment
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.True(t, decoded.InitiatedOn.Equal(initiatedOn.Time))
+}
+
+func TestRefundsListOptions_Time_Filters(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	testdata, err := ioutil.ReadFile("testdata/list_refunds.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testdata = bytes.Replace(testdata, []byte("PAGE_SIZE"), []byte("10"), 1)
+	testdata = bytes.Replace(testdata, []byte("NEXT_PAGE_NUMBER"), []byte("0"), 1)
+
+	gock.New(TestBaseUrl).
+		Get("/v1/refunds").
+		MatchParam("createdBefore", "^2020-01-02T15:04$").
+		MatchParam("createdAfter", "^2019-12-31T23:00$").
+		Reply(200).
+		JSON(testdata)
+
+	client := New("test", "test", &Config{URL: TestBaseUrl})
+
+	copenhagen := time.FixedZone("CET", 3600)
+	opts := &RefundsListOptions{
+		CreatedBefore: time.Date(2020, 1, 2, 15, 4, 59, 0, time.UTC),
+		CreatedAfter:  time.Date(2020, 1, 1, 0, 0, 0, 0, copenhagen),
+	}
+
+	refunds, err := client.Payment.Refund.List(context.TODO(), opts)
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, copenhagen), opts.CreatedAfter, "the options must not be modified")
+
+	for _, refund := range refunds.Refunds {
+		assert.Equal(t, time.Date(2021, 7, 19, 12, 42, 38, 0, time.UTC), refund.CreatedOn.UTC())
+	}
+}
+
+func TestRefundsListOptions_Zero_Filters_Are_Omitted(t *testing.T) {
+	path, err := addOptions(refundsBasePath, &RefundsListOptions{PaymentId: "211444eb-1c4e-4194-a58f-905d97877cc5"})
+	assert.Nil(t, err)
+	assert.Equal(t, "v1/refunds?pageNumber=0&pageSize=0&paymentId=211444eb-1c4e-4194-a58f-905d97877cc5", path)
+}
@@ -0,0 +1,22 @@
+package mobilepay
+
+import (
+	"context"
+	"net/http"
+)
+
+// Tracer starts a span around every call to the MobilePay API made through Client.Do.
+//
+// The mobilepayotel module provides an OpenTelemetry implementation.
+type Tracer interface {
+	// Start is called before the request is sent, including any retries. It may add trace
+	// context headers to req. The returned context is used to send the request.
+	Start(ctx context.Context, op Operation, req *http.Request) (context.Context, Span)
+}
+
+// Span is the span of a single call to the MobilePay API.
+type Span interface {
+	// End is called once the call has completed. resp is nil when no response was received and
+	// err is the decoded error, e.g. an *ErrorResponse.
+	End(resp *http.Response, err error)
+}
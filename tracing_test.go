@@ -0,0 +1,86 @@
+package mobilepay
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	op     Operation
+	status int
+	err    error
+	ended  bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, op Operation, req *http.Request) (context.Context, Span) {
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	span := &recordingSpan{op: op}
+	t.spans = append(t.spans, span)
+
+	return ctx, span
+}
+
+func (s *recordingSpan) End(resp *http.Response, err error) {
+	if resp != nil {
+		s.status = resp.StatusCode
+	}
+	s.err = err
+	s.ended = true
+}
+
+func TestTracer_Operations(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/payments/206d2b31-ff25-4414-9fd1-bfe9807fa8b7/capture").
+		MatchHeader("traceparent", "^00-4bf92f3577b34da6a3ce929d0e0e4736-").
+		Reply(204)
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks/e4a2e195-74f6-42e1-a172-83291c9d2a41").
+		Reply(404)
+
+	tracer := &recordingTracer{}
+	client, err := NewWithOptions("test", "test", WithBaseURL(TestBaseUrl), WithTracer(tracer))
+	assert.Nil(t, err)
+
+	err = client.Payment.Capture(context.TODO(), "206d2b31-ff25-4414-9fd1-bfe9807fa8b7", 100)
+	assert.Nil(t, err)
+
+	_, err = client.Webhook.Find(context.TODO(), "e4a2e195-74f6-42e1-a172-83291c9d2a41")
+	assert.Error(t, err)
+
+	assert.Len(t, tracer.spans, 2)
+	assert.Equal(t, Operation{Name: "Payment.Capture", Route: "v1/payments/{id}/capture"}, tracer.spans[0].op)
+	assert.Equal(t, 204, tracer.spans[0].status)
+	assert.Nil(t, tracer.spans[0].err)
+	assert.True(t, tracer.spans[0].ended)
+
+	assert.Equal(t, Operation{Name: "Webhook.Find", Route: "v1/webhooks/{id}"}, tracer.spans[1].op)
+	assert.Equal(t, 404, tracer.spans[1].status)
+	assert.True(t, IsNotFound(tracer.spans[1].err))
+	assert.True(t, gock.IsDone())
+}
+
+func TestOperationFromContext(t *testing.T) {
+	_, ok := OperationFromContext(context.Background())
+	assert.False(t, ok)
+
+	op, ok := OperationFromContext(withOperation(context.Background(), "Refund.List", "v1/refunds"))
+	assert.True(t, ok)
+	assert.Equal(t, "Refund.List", op.Name)
+	assert.Equal(t, "v1/refunds", op.Route)
+}
+
+func TestWithTracer_Nil(t *testing.T) {
+	_, err := NewWithOptions("test", "test", WithTracer(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package mobilepay
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Limits of the MobilePay API checked before a request is sent.
+const (
+	MaxReferenceLength   = 64
+	MaxDescriptionLength = 1000
+	MaxRedirectUriLength = 1000
+	MaxWebhookUrlLength  = 1000
+)
+
+// Validate checks the params before a payment is created. An empty idempotency key is allowed,
+// as it may be filled in by the client's IdempotencyKeyProvider.
+func (p *PaymentParams) Validate() error {
+	return p.validate(validateOptionalUUID)
+}
+
+// validate checks the params using validateKey for the idempotency key, which is required
+// once the client has resolved it.
+func (p *PaymentParams) validate(validateKey func(v *validator, arg, value string)) error {
+	v := &validator{}
+
+	validateAmount(v, p.Amount)
+	validateKey(v, "idempotencyKey", p.IdempotencyKey)
+	validateUUID(v, "paymentPointId", p.PaymentPointId)
+	validateRedirectUri(v, p.RedirectUri)
+	validateLength(v, "reference", p.Reference, MaxReferenceLength)
+	validateLength(v, "description", p.Description, MaxDescriptionLength)
+
+	return v.err()
+}
+
+// Validate checks the params before a refund is created. An empty idempotency key is allowed,
+// as it may be filled in by the client's IdempotencyKeyProvider.
+func (p *RefundParams) Validate() error {
+	return p.validate(validateOptionalUUID)
+}
+
+// validate checks the params using validateKey for the idempotency key, which is required
+// once the client has resolved it.
+func (p *RefundParams) validate(validateKey func(v *validator, arg, value string)) error {
+	v := &validator{}
+
+	validateAmount(v, p.Amount)
+	validateKey(v, "idempotencyKey", p.IdempotencyKey)
+	validateUUID(v, "paymentId", p.PaymentId)
+	validateLength(v, "reference", p.Reference, MaxReferenceLength)
+	validateLength(v, "description", p.Description, MaxDescriptionLength)
+
+	return v.err()
+}
+
+// Validate checks the params before a webhook is created.
+func (p *WebhookCreateParams) Validate() error {
+	v := &validator{}
+
+	validateWebhookUrl(v, p.Url)
+	validateEvents(v, p.Events)
+
+	return v.err()
+}
+
+// Validate checks the params before a webhook is updated.
+func (p *WebhookUpdateParams) Validate() error {
+	v := &validator{}
+
+	validateWebhookUrl(v, p.Url)
+	validateEvents(v, p.Events)
+
+	return v.err()
+}
+
+// validateId checks an id that becomes part of the request path. Anything but a UUID could
+// address another resource, e.g. "../webhooks".
+func validateId(arg, id string) error {
+	v := &validator{}
+	validateUUID(v, arg, id)
+
+	if len(v.errors) == 0 {
+		return nil
+	}
+
+	return v.errors[0]
+}
+
+func validateAmount(v *validator, amount int) {
+	if amount <= 0 {
+		v.add("amount", "it must be positive")
+	}
+}
+
+func validateUUID(v *validator, arg, value string) {
+	if value == "" {
+		v.add(arg, "cannot be empty")
+		return
+	}
+
+	validateOptionalUUID(v, arg, value)
+}
+
+func validateOptionalUUID(v *validator, arg, value string) {
+	if value != "" && !isUUID(value) {
+		v.add(arg, "it must be a valid UUID")
+	}
+}
+
+func validateLength(v *validator, arg, value string, max int) {
+	if n := len([]rune(value)); n > max {
+		v.add(arg, fmt.Sprintf("it is %d characters long, the maximum is %d", n, max))
+	}
+}
+
+// blockedRedirectSchemes are schemes that are never an app, even when followed by "://".
+var blockedRedirectSchemes = map[string]bool{
+	"http":       true,
+	"javascript": true,
+	"vbscript":   true,
+	"data":       true,
+	"file":       true,
+	"ftp":        true,
+}
+
+// validateRedirectUri accepts HTTPS URLs and app schemes like myapp://callback.
+func validateRedirectUri(v *validator, redirectUri string) {
+	if redirectUri == "" {
+		v.add("redirectUri", "cannot be empty")
+		return
+	}
+
+	validateLength(v, "redirectUri", redirectUri, MaxRedirectUriLength)
+
+	u, err := url.Parse(redirectUri)
+	if err != nil || u.Scheme == "" {
+		v.add("redirectUri", "it must be an absolute URI")
+		return
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	switch {
+	case scheme == "https":
+		if u.Host == "" {
+			v.add("redirectUri", "it must be an absolute URI")
+		}
+	case blockedRedirectSchemes[scheme] || !strings.HasPrefix(redirectUri[len(scheme):], "://"):
+		v.add("redirectUri", "it must use HTTPS or an app scheme")
+	}
+}
+
+// validateWebhookUrl accepts HTTPS URLs. Plain HTTP is allowed for loopback hosts only, so
+// webhooks can be tested against a local server.
+func validateWebhookUrl(v *validator, webhookUrl string) {
+	if webhookUrl == "" {
+		v.add("url", "cannot be empty")
+		return
+	}
+
+	validateLength(v, "url", webhookUrl, MaxWebhookUrlLength)
+
+	u, err := url.Parse(webhookUrl)
+	if err != nil || u.Host == "" {
+		v.add("url", "it must be an absolute URL")
+		return
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+	case "http":
+		if !isLoopback(u.Hostname()) {
+			v.add("url", "it must use HTTPS")
+		}
+	default:
+		v.add("url", "it must use HTTPS")
+	}
+}
+
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func validateEvents(v *validator, events []WebhookEvent) {
+	if len(events) == 0 {
+		v.add("events", "at least one event is required")
+		return
+	}
+
+	for _, event := range events {
+		switch event.Enum() {
+		case Unknown:
+			v.add("events", fmt.Sprintf("%q is not a known event", event))
+		case TestNotification:
+			v.add("events", fmt.Sprintf("%q cannot be subscribed to, use PublishTestNotification", event))
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package mobilepay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validPaymentParams() *PaymentParams {
+	return &PaymentParams{
+		Amount:         1050,
+		IdempotencyKey: "223127e5-0d53-4f72-a37e-57d1b5e5b3d6",
+		PaymentPointId: "1f8ed17f-f310-4f40-a7a4-df78185efbdd",
+		RedirectUri:    "myapp://redirect",
+		Reference:      "order-1",
+		Description:    "this is a test payment",
+	}
+}
+
+func validRefundParams() *RefundParams {
+	return &RefundParams{
+		Amount:         100,
+		IdempotencyKey: "7576910d-9789-4fef-a72e-877d89afec94",
+		PaymentId:      "211444eb-1c4e-4194-a58f-905d97877cc5",
+		Reference:      "refund-1",
+	}
+}
+
+func validationArgs(t *testing.T, err error) []string {
+	var validationErr *ValidationError
+	if !assert.True(t, errors.As(err, &validationErr), "expected a ValidationError, got %v", err) {
+		return nil
+	}
+
+	args := make([]string, len(validationErr.Errors))
+	for i, argErr := range validationErr.Errors {
+		args[i] = argErr.Arg
+	}
+
+	return args
+}
+
+func TestValidate_PaymentParams(t *testing.T) {
+	assert.Nil(t, validPaymentParams().Validate())
+
+	params := validPaymentParams()
+	params.IdempotencyKey = ""
+	assert.Nil(t, params.Validate())
+
+	params.RedirectUri = "https://example.com/callback"
+	assert.Nil(t, params.Validate())
+
+	tests := map[string]func(p *PaymentParams){
+		"amount":         func(p *PaymentParams) { p.Amount = 0 },
+		"idempotencyKey": func(p *PaymentParams) { p.IdempotencyKey = "not-a-uuid" },
+		"paymentPointId": func(p *PaymentParams) { p.PaymentPointId = "" },
+		"redirectUri":    func(p *PaymentParams) { p.RedirectUri = "http://example.com/callback" },
+		"reference":      func(p *PaymentParams) { p.Reference = strings.Repeat("a", MaxReferenceLength+1) },
+		"description":    func(p *PaymentParams) { p.Description = strings.Repeat("a", MaxDescriptionLength+1) },
+	}
+
+	for arg, invalidate := range tests {
+		params := validPaymentParams()
+		invalidate(params)
+		assert.Equal(t, []string{arg}, validationArgs(t, params.Validate()), arg)
+	}
+
+	for _, redirectUri := range []string{"myapp://redirect", "com.example.app://callback?order=1", "HTTPS://example.com"} {
+		params = validPaymentParams()
+		params.RedirectUri = redirectUri
+		assert.Nil(t, params.Validate(), redirectUri)
+	}
+
+	for _, redirectUri := range []string{
+		"/callback",
+		"https:callback",
+		"myapp:callback",
+		"javascript:alert(1)",
+		"javascript://%0Aalert(1)",
+		"JavaScript:alert(1)",
+		"data:text/html,<script>alert(1)</script>",
+		"file:///etc/passwd",
+		"ftp://example.com/callback",
+		"HTTP://example.com/callback",
+	} {
+		params = validPaymentParams()
+		params.RedirectUri = redirectUri
+		assert.Equal(t, []string{"redirectUri"}, validationArgs(t, params.Validate()), redirectUri)
+	}
+}
+
+func TestValidate_RefundParams(t *testing.T) {
+	assert.Nil(t, validRefundParams().Validate())
+
+	tests := map[string]func(p *RefundParams){
+		"amount":         func(p *RefundParams) { p.Amount = -1 },
+		"idempotencyKey": func(p *RefundParams) { p.IdempotencyKey = "not-a-uuid" },
+		"paymentId":      func(p *RefundParams) { p.PaymentId = "211444eb" },
+		"reference":      func(p *RefundParams) { p.Reference = strings.Repeat("a", MaxReferenceLength+1) },
+		"description":    func(p *RefundParams) { p.Description = strings.Repeat("a", MaxDescriptionLength+1) },
+	}
+
+	for arg, invalidate := range tests {
+		params := validRefundParams()
+		invalidate(params)
+		assert.Equal(t, []string{arg}, validationArgs(t, params.Validate()), arg)
+	}
+}
+
+func TestValidate_WebhookParams(t *testing.T) {
+	params := &WebhookCreateParams{
+		Events: []WebhookEvent{PaymentReserved.Name()},
+		Url:    "https://my-api.com/webhooks",
+	}
+	assert.Nil(t, params.Validate())
+
+	params.Url = "http://127.0.0.1:8080/webhooks"
+	assert.Nil(t, params.Validate())
+
+	params.Url = "http://my-api.com/webhooks"
+	assert.Equal(t, []string{"url"}, validationArgs(t, params.Validate()))
+
+	update := &WebhookUpdateParams{Url: "https://my-api.com/webhooks"}
+	assert.Equal(t, []string{"events"}, validationArgs(t, update.Validate()))
+
+	update.Events = []WebhookEvent{"payment.unknown"}
+	assert.Equal(t, []string{"events"}, validationArgs(t, update.Validate()))
+
+	update.Events = []WebhookEvent{PaymentReserved.Name(), TestNotification.Name()}
+	assert.Equal(t, []string{"events"}, validationArgs(t, update.Validate()))
+}
+
+func TestValidate_Collects_All_Errors(t *testing.T) {
+	err := (&PaymentParams{Reference: strings.Repeat("a", MaxReferenceLength+1)}).Validate()
+	assert.Equal(t, []string{"amount", "paymentPointId", "redirectUri", "reference"}, validationArgs(t, err))
+	assert.True(t, strings.HasPrefix(err.Error(), "validation failed: amount is invalid because it must be positive; "))
+
+	var argErr *ArgError
+	assert.True(t, errors.As(err, &argErr))
+	assert.Equal(t, "amount", argErr.Arg)
+	assert.Equal(t, "it must be positive", argErr.Reason)
+}
+
+func TestValidate_Rejects_Before_Sending(t *testing.T) {
+	transport := &countingTransport{}
+	client, err := NewWithOptions("test", "test", WithBaseURL(TestBaseUrl), WithHTTPClient(&http.Client{Transport: transport}))
+	assert.Nil(t, err)
+
+	payment := validPaymentParams()
+	payment.Amount = 0
+	_, err = client.Payment.Create(context.TODO(), payment)
+	assert.Equal(t, []string{"amount"}, validationArgs(t, err))
+
+	refund := validRefundParams()
+	refund.PaymentId = ""
+	_, err = client.Payment.Refund.Create(context.TODO(), refund)
+	assert.Equal(t, []string{"paymentId"}, validationArgs(t, err))
+
+	_, err = client.Payment.Create(context.TODO(), &PaymentParams{
+		Amount:         -1,
+		IdempotencyKey: "bad",
+		PaymentPointId: "nope",
+		RedirectUri:    "http://x",
+	})
+	assert.Equal(t, []string{"amount", "idempotencyKey", "paymentPointId", "redirectUri"}, validationArgs(t, err))
+
+	_, err = client.Payment.Refund.Create(context.TODO(), &RefundParams{Amount: 100})
+	assert.Equal(t, []string{"idempotencyKey", "paymentId"}, validationArgs(t, err))
+
+	_, err = client.Webhook.Create(context.TODO(), &WebhookCreateParams{Url: "https://my-api.com/webhooks"})
+	assert.Equal(t, []string{"events"}, validationArgs(t, err))
+
+	_, err = client.Webhook.Update(context.TODO(), "e4a2e195-74f6-42e1-a172-83291c9d2a41", nil)
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, transport.requests)
+}
+
+func TestValidate_Ids_And_Amounts_Before_Sending(t *testing.T) {
+	transport := &countingTransport{}
+	client, err := NewWithOptions("test", "test", WithBaseURL(TestBaseUrl), WithHTTPClient(&http.Client{Transport: transport}))
+	assert.Nil(t, err)
+
+	ctx := context.TODO()
+	paymentId := "186d2b31-ff25-4414-9fd1-bfe9807fa8b7"
+
+	assertArgError := func(arg string, err error) {
+		var argErr *ArgError
+		if assert.True(t, errors.As(err, &argErr), "expected an ArgError, got %v", err) {
+			assert.Equal(t, arg, argErr.Arg)
+		}
+	}
+
+	_, err = client.Payment.Find(ctx, "../webhooks")
+	assertArgError("paymentId", err)
+	assertArgError("paymentId", client.Payment.Cancel(ctx, "../webhooks"))
+	assertArgError("paymentId", client.Payment.Capture(ctx, "", 100))
+	assert.Equal(t, []string{"amount"}, validationArgs(t, client.Payment.Capture(ctx, paymentId, 0)))
+	assert.Equal(t, []string{"paymentId", "amount"}, validationArgs(t, client.Payment.Capture(ctx, "x", -1)))
+
+	_, err = client.Webhook.Find(ctx, "../payments")
+	assertArgError("webhookId", err)
+	_, err = client.Webhook.Update(ctx, "../payments", &WebhookUpdateParams{
+		Url:    "https://my-api.com/webhooks",
+		Events: []WebhookEvent{PaymentReserved.Name()},
+	})
+	assertArgError("webhookId", err)
+	assertArgError("webhookId", client.Webhook.Delete(ctx, ""))
+	assertArgError("webhookId", client.Webhook.PublishTestNotification(ctx, "../payments"))
+
+	_, err = client.PaymentPoint.Find(ctx, "../payments")
+	assertArgError("paymentPointId", err)
+
+	assert.Equal(t, 0, transport.requests)
+}
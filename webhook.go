@@ -21,10 +21,24 @@ const (
 	PaymentReserved
 	PaymentExpired
 	PaymentPointActivated
+	TestNotification
 )
 
+var webhookEventNames = [...]WebhookEvent{"Unknown", "payment.reserved", "payment.expired", "paymentpoint.activated", "test.notification"}
+
 func (webhookEvent WebhookEventEnum) Name() WebhookEvent {
-	return [...]WebhookEvent{"Unknown", "payment.reserved", "payment.expired", "paymentpoint.activated"}[webhookEvent]
+	return webhookEventNames[webhookEvent]
+}
+
+// Enum returns the WebhookEventEnum of the event or Unknown if MobilePay does not document the event.
+func (webhookEvent WebhookEvent) Enum() WebhookEventEnum {
+	for i, name := range webhookEventNames {
+		if i != int(Unknown) && name == webhookEvent {
+			return WebhookEventEnum(i)
+		}
+	}
+
+	return Unknown
 }
 
 type WebhookService interface {
@@ -75,6 +89,8 @@ type WebhooksRoot struct {
 func (s WebhookServiceOp) Get(ctx context.Context) (*WebhooksRoot, error) {
 	path := webhooksBasePath
 
+	ctx = withOperation(ctx, "Webhook.Get", webhooksBasePath)
+
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
@@ -89,14 +105,41 @@ func (s WebhookServiceOp) Get(ctx context.Context) (*WebhooksRoot, error) {
 	return root, nil
 }
 
+// All returns an iterator over all webhooks.
+func (s WebhookServiceOp) All(ctx context.Context) *WebhookIterator {
+	fetch := func(ctx context.Context, pageNumber int) (*page, error) {
+		root, err := s.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]interface{}, len(root.Webhooks))
+		for i, webhook := range root.Webhooks {
+			items[i] = webhook
+		}
+
+		return &page{items: items}, nil
+	}
+
+	return &WebhookIterator{newIter(ctx, 1, false, fetch)}
+}
+
 // Create webhook
 func (s *WebhookServiceOp) Create(ctx context.Context, createRequest *WebhookCreateParams) (*Webhook, error) {
 	if createRequest == nil {
 		return nil, newArgError("createRequest", "cannot be nil")
 	}
 
+	if err := createRequest.Validate(); err != nil {
+		s.client.Logger.Errorf("invalid createRequest: %v", err)
+
+		return nil, err
+	}
+
 	path := webhooksBasePath
 
+	ctx = withOperation(ctx, "Webhook.Create", webhooksBasePath)
+
 	req, err := s.client.NewRequest(ctx, http.MethodPost, path, createRequest)
 	if err != nil {
 		return nil, err
@@ -113,8 +156,16 @@ func (s *WebhookServiceOp) Create(ctx context.Context, createRequest *WebhookCre
 
 // Get individual webhook. It requires a non-empty webhook id.
 func (s *WebhookServiceOp) Find(ctx context.Context, webhookId string) (*Webhook, error) {
+	if err := validateId("webhookId", webhookId); err != nil {
+		s.client.Logger.Errorf("invalid webhookId: %v", err)
+
+		return nil, err
+	}
+
 	path := fmt.Sprintf("%s/%s", webhooksBasePath, webhookId)
 
+	ctx = withOperation(ctx, "Webhook.Find", webhooksBasePath+"/{id}")
+
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
@@ -130,8 +181,26 @@ func (s *WebhookServiceOp) Find(ctx context.Context, webhookId string) (*Webhook
 }
 
 func (s WebhookServiceOp) Update(ctx context.Context, webhookId string, request *WebhookUpdateParams) (*Webhook, error) {
+	if err := validateId("webhookId", webhookId); err != nil {
+		s.client.Logger.Errorf("invalid webhookId: %v", err)
+
+		return nil, err
+	}
+
+	if request == nil {
+		return nil, newArgError("request", "cannot be nil")
+	}
+
+	if err := request.Validate(); err != nil {
+		s.client.Logger.Errorf("invalid request: %v", err)
+
+		return nil, err
+	}
+
 	path := fmt.Sprintf("%s/%s", webhooksBasePath, webhookId)
 
+	ctx = withOperation(ctx, "Webhook.Update", webhooksBasePath+"/{id}")
+
 	req, err := s.client.NewRequest(ctx, http.MethodPut, path, request)
 	if err != nil {
 		return nil, err
@@ -147,8 +216,16 @@ func (s WebhookServiceOp) Update(ctx context.Context, webhookId string, request
 }
 
 func (s *WebhookServiceOp) Delete(ctx context.Context, webhookId string) error {
+	if err := validateId("webhookId", webhookId); err != nil {
+		s.client.Logger.Errorf("invalid webhookId: %v", err)
+
+		return err
+	}
+
 	path := fmt.Sprintf("%s/%s", webhooksBasePath, webhookId)
 
+	ctx = withOperation(ctx, "Webhook.Delete", webhooksBasePath+"/{id}")
+
 	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, nil)
 	if err != nil {
 		return err
@@ -161,3 +238,29 @@ func (s *WebhookServiceOp) Delete(ctx context.Context, webhookId string) error {
 
 	return nil
 }
+
+// PublishTestNotification makes MobilePay send a test.notification to the webhook.
+// The notification is delivered asynchronously, after this method has returned.
+func (s *WebhookServiceOp) PublishTestNotification(ctx context.Context, webhookId string) error {
+	if err := validateId("webhookId", webhookId); err != nil {
+		s.client.Logger.Errorf("invalid webhookId: %v", err)
+
+		return err
+	}
+
+	path := fmt.Sprintf("%s/%s/publishtestnotification", webhooksBasePath, webhookId)
+
+	ctx = withOperation(ctx, "Webhook.PublishTestNotification", webhooksBasePath+"/{id}/publishtestnotification")
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package mobilepay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// DefaultWebhookMaxBodyBytes is the default maximum size of a webhook request body.
+const DefaultWebhookMaxBodyBytes = 64 << 10
+
+// WebhookHandlerFunc handles a verified webhook notification. Returning an error makes
+// the WebhookHandler answer with a 500 status code, so MobilePay delivers the notification again.
+type WebhookHandlerFunc func(ctx context.Context, notification *WebhookNotification) error
+
+// WebhookHandler is an http.Handler that verifies incoming MobilePay webhooks and
+// dispatches the notifications to the callbacks registered for their event.
+//
+// It answers 405 to anything but POST, 413 if the body is larger than MaxBodyBytes,
+// 401 if the signature is missing or invalid and 400 if the notification is malformed.
+// Verified notifications without a registered callback are acknowledged with 200.
+type WebhookHandler struct {
+	// MaxBodyBytes is the maximum size of the request body.
+	MaxBodyBytes int64
+
+	// Logger is used to log rejected webhooks and failing callbacks.
+	Logger LeveledLoggerInterface
+
+	webhookUrl   string
+	signatureKey string
+
+	mu       sync.RWMutex
+	handlers map[WebhookEvent]WebhookHandlerFunc
+
+	// fallback handles the notifications without a registered callback, if set.
+	fallback WebhookHandlerFunc
+}
+
+var _ http.Handler = &WebhookHandler{}
+
+// NewWebhookHandler returns a WebhookHandler for the webhook with the given url and signature key.
+// webhookUrl is the url used to create the webhook and signatureKey is returned by MobilePay when
+// the webhook is created.
+func NewWebhookHandler(webhookUrl, signatureKey string) *WebhookHandler {
+	return &WebhookHandler{
+		MaxBodyBytes: DefaultWebhookMaxBodyBytes,
+		Logger:       DefaultLeveledLogger,
+		webhookUrl:   webhookUrl,
+		signatureKey: signatureKey,
+		handlers:     make(map[WebhookEvent]WebhookHandlerFunc),
+	}
+}
+
+// On registers the callback for the given event, replacing any previous callback.
+func (h *WebhookHandler) On(event WebhookEvent, fn WebhookHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.handlers[event] = fn
+}
+
+// OnPaymentReserved registers the callback for the payment.reserved event.
+func (h *WebhookHandler) OnPaymentReserved(fn WebhookHandlerFunc) {
+	h.On(PaymentReserved.Name(), fn)
+}
+
+// OnPaymentExpired registers the callback for the payment.expired event.
+func (h *WebhookHandler) OnPaymentExpired(fn WebhookHandlerFunc) {
+	h.On(PaymentExpired.Name(), fn)
+}
+
+// OnPaymentPointActivated registers the callback for the paymentpoint.activated event.
+func (h *WebhookHandler) OnPaymentPointActivated(fn WebhookHandlerFunc) {
+	h.On(PaymentPointActivated.Name(), fn)
+}
+
+// OnTestNotification registers the callback for the test.notification event.
+func (h *WebhookHandler) OnTestNotification(fn WebhookHandlerFunc) {
+	h.On(TestNotification.Name(), fn)
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	maxBodyBytes := h.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultWebhookMaxBodyBytes
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if int64(len(body)) > maxBodyBytes {
+		h.logger().Warnf("Rejected webhook with a body larger than %d bytes", maxBodyBytes)
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	notification, err := ParseWebhookNotification(body, r.Header, h.webhookUrl, h.signatureKey)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, ErrInvalidWebhookSignature):
+			// never log the error itself, the verifier may have put the expected signature in it.
+			h.logger().Warnf("Rejected webhook: invalid signature")
+			status = http.StatusUnauthorized
+		case errors.Is(err, ErrMissingVerifierProperties):
+			h.logger().Warnf("Rejected webhook: missing signature")
+			status = http.StatusUnauthorized
+		default:
+			h.logger().Warnf("Rejected webhook: %v", err)
+		}
+
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	h.mu.RLock()
+	fn := h.handlers[notification.EventType]
+	if fn == nil {
+		fn = h.fallback
+	}
+	h.mu.RUnlock()
+
+	if fn != nil {
+		if err := fn(r.Context(), notification); err != nil {
+			h.logger().Errorf("Webhook callback for %v notification %v failed: %v",
+				notification.EventType, notification.NotificationId, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) logger() LeveledLoggerInterface {
+	if h.Logger == nil {
+		return DefaultLeveledLogger
+	}
+
+	return h.Logger
+}
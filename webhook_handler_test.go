@@ -0,0 +1,137 @@
+package mobilepay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const reservedBody = `{"notificationId":"a1b2c3d4-59c3-430c-a402-d74641dd8555","eventType":"payment.reserved","eventDate":"2022-02-20T16:35:28Z","data":{"type":"payment","id":"186d2b31-ff25-4414-9fd1-bfe9807fa8b7"}}`
+
+func newWebhookRequest(method, body string, header http.Header) *http.Request {
+	req := httptest.NewRequest(method, validUrl, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	return req
+}
+
+func TestWebhookHandler_Dispatch(t *testing.T) {
+	handler := NewWebhookHandler(validUrl, validSecret)
+
+	var reserved, expired []string
+	handler.OnPaymentReserved(func(ctx context.Context, n *WebhookNotification) error {
+		reserved = append(reserved, n.Data.Id)
+		return nil
+	})
+	handler.OnPaymentExpired(func(ctx context.Context, n *WebhookNotification) error {
+		expired = append(expired, n.Data.Id)
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newWebhookRequest(http.MethodPost, reservedBody, signedHeader(validUrl, validSecret, reservedBody)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"186d2b31-ff25-4414-9fd1-bfe9807fa8b7"}, reserved)
+	assert.Empty(t, expired)
+}
+
+func TestWebhookHandler_Unregistered_Event(t *testing.T) {
+	handler := NewWebhookHandler(validUrl, validSecret)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newWebhookRequest(http.MethodPost, validBody, newHeader(true)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestWebhookHandler_Method_Not_Allowed(t *testing.T) {
+	handler := NewWebhookHandler(validUrl, validSecret)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newWebhookRequest(http.MethodGet, "", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+}
+
+func TestWebhookHandler_Invalid_Signature(t *testing.T) {
+	handler := NewWebhookHandler(validUrl, validSecret)
+	handler.Logger = &LeveledLogger{Level: LevelNull}
+
+	called := false
+	handler.OnTestNotification(func(ctx context.Context, n *WebhookNotification) error {
+		called = true
+		return nil
+	})
+
+	for _, header := range []http.Header{newHeader(false), signedHeader(validUrl, invalidSecret, validBody)} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newWebhookRequest(http.MethodPost, validBody, header))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	}
+
+	assert.False(t, called)
+}
+
+func TestWebhookHandler_Invalid_Signature_Not_Logged(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	handler := NewWebhookHandler(validUrl, validSecret)
+	handler.Logger = &LeveledLogger{Level: LevelDebug, stdoutOverride: &stdout, stderrOverride: &stderr}
+
+	forged := signedHeader(validUrl, invalidSecret, reservedBody)
+	expected := signedHeader(validUrl, validSecret, reservedBody).Get("x-mobilepay-signature")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newWebhookRequest(http.MethodPost, reservedBody, forged))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, stderr.String()+stdout.String(), "Rejected webhook: invalid signature")
+	assert.NotContains(t, stderr.String()+stdout.String(), expected)
+}
+
+func TestWebhookHandler_Body_Too_Large(t *testing.T) {
+	handler := NewWebhookHandler(validUrl, validSecret)
+	handler.Logger = &LeveledLogger{Level: LevelNull}
+	handler.MaxBodyBytes = 16
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newWebhookRequest(http.MethodPost, validBody, newHeader(true)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestWebhookHandler_Malformed_Notification(t *testing.T) {
+	handler := NewWebhookHandler(validUrl, validSecret)
+	handler.Logger = &LeveledLogger{Level: LevelNull}
+
+	body := `{"notificationId":"1"}`
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newWebhookRequest(http.MethodPost, body, signedHeader(validUrl, validSecret, body)))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebhookHandler_Callback_Error(t *testing.T) {
+	handler := NewWebhookHandler(validUrl, validSecret)
+	handler.Logger = &LeveledLogger{Level: LevelNull}
+
+	handler.OnPaymentReserved(func(ctx context.Context, n *WebhookNotification) error {
+		return errors.New("database unavailable")
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newWebhookRequest(http.MethodPost, reservedBody, signedHeader(validUrl, validSecret, reservedBody)))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,95 @@
+package mobilepay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookDataType is the type of the resource a webhook notification refers to.
+type WebhookDataType string
+
+const (
+	WebhookDataTypePayment      WebhookDataType = "payment"
+	WebhookDataTypePaymentPoint WebhookDataType = "paymentpoint"
+	WebhookDataTypeTest         WebhookDataType = "test"
+)
+
+// WebhookNotification is the payload MobilePay sends to a webhook.
+type WebhookNotification struct {
+	NotificationId string                  `json:"notificationId"`
+	EventType      WebhookEvent            `json:"eventType"`
+	EventDate      time.Time               `json:"eventDate"`
+	Data           WebhookNotificationData `json:"data"`
+}
+
+// WebhookNotificationData identifies the resource a webhook notification refers to,
+// e.g. the payment that was reserved.
+type WebhookNotificationData struct {
+	Type WebhookDataType `json:"type"`
+	Id   string          `json:"id"`
+}
+
+// ParseWebhookNotification verifies the signature of an incoming webhook and parses its body.
+// The signature is checked first, so the body is never parsed if it was not sent by MobilePay.
+// webhookUrl and webhookSignatureKey are the ones used by NewWebhooksVerifier.
+func ParseWebhookNotification(body []byte, header http.Header, webhookUrl, webhookSignatureKey string) (*WebhookNotification, error) {
+	verifier, err := NewWebhooksVerifier(header, webhookUrl, webhookSignatureKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := verifier.Write(body); err != nil {
+		return nil, err
+	}
+
+	// the error of Ensure contains the expected signature, which must not end up in logs.
+	if err := verifier.Ensure(); err != nil {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	return decodeWebhookNotification(body)
+}
+
+// decodeWebhookNotification parses the body of a webhook that has already been verified.
+func decodeWebhookNotification(body []byte) (*WebhookNotification, error) {
+	var raw struct {
+		NotificationId string                  `json:"notificationId"`
+		EventType      WebhookEvent            `json:"eventType"`
+		EventDate      string                  `json:"eventDate"`
+		Data           WebhookNotificationData `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("%w: malformed json: %v", ErrInvalidWebhookNotification, err)
+	}
+
+	if raw.NotificationId == "" {
+		return nil, fmt.Errorf("%w: notificationId is missing", ErrInvalidWebhookNotification)
+	}
+
+	if raw.EventType.Enum() == Unknown {
+		return nil, fmt.Errorf("%w: unknown eventType %q", ErrInvalidWebhookNotification, raw.EventType)
+	}
+
+	if raw.EventDate == "" {
+		return nil, fmt.Errorf("%w: eventDate is missing", ErrInvalidWebhookNotification)
+	}
+
+	eventDate, err := ParseTimestamp(raw.EventDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed eventDate %q", ErrInvalidWebhookNotification, raw.EventDate)
+	}
+
+	if raw.Data.Type == "" || raw.Data.Id == "" {
+		return nil, fmt.Errorf("%w: data must have a type and an id", ErrInvalidWebhookNotification)
+	}
+
+	return &WebhookNotification{
+		NotificationId: raw.NotificationId,
+		EventType:      raw.EventType,
+		EventDate:      eventDate.Time,
+		Data:           raw.Data,
+	}, nil
+}
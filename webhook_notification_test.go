@@ -0,0 +1,93 @@
+package mobilepay
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	b64 "encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func signedHeader(webhookUrl, signatureKey, body string) http.Header {
+	mac := hmac.New(sha1.New, []byte(signatureKey))
+	mac.Write([]byte(webhookUrl + body))
+
+	h := http.Header{}
+	h.Set("x-mobilepay-signature", b64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	return h
+}
+
+func TestParseWebhookNotification(t *testing.T) {
+	notification, err := ParseWebhookNotification([]byte(validBody), newHeader(true), validUrl, validSecret)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "4352f1ae-59c3-430c-a402-d74641dd8555", notification.NotificationId)
+	assert.Equal(t, TestNotification.Name(), notification.EventType)
+	assert.Equal(t, time.Date(2022, 2, 20, 16, 35, 28, 0, time.UTC), notification.EventDate)
+	assert.Equal(t, WebhookDataTypeTest, notification.Data.Type)
+	assert.Equal(t, "57ff4ddf-575f-4c4a-99c8-b190a1e1f316", notification.Data.Id)
+}
+
+func TestParseWebhookNotification_Payment_Reserved(t *testing.T) {
+	body := `{"notificationId":"a1b2c3d4-59c3-430c-a402-d74641dd8555","eventType":"payment.reserved","eventDate":"2022-02-20T16:35:28+01:00","data":{"type":"payment","id":"186d2b31-ff25-4414-9fd1-bfe9807fa8b7"}}`
+
+	notification, err := ParseWebhookNotification([]byte(body), signedHeader(validUrl, validSecret, body), validUrl, validSecret)
+
+	assert.Nil(t, err)
+	assert.Equal(t, PaymentReserved, notification.EventType.Enum())
+	assert.True(t, notification.EventDate.Equal(time.Date(2022, 2, 20, 15, 35, 28, 0, time.UTC)))
+	assert.Equal(t, WebhookDataTypePayment, notification.Data.Type)
+}
+
+func TestParseWebhookNotification_Event_Date_Formats(t *testing.T) {
+	for _, eventDate := range []string{"2022-02-20T16:35:28.1234567Z", "2022-02-20T16:35:28.1234567", "2022-02-20T16:35"} {
+		body := `{"notificationId":"1","eventType":"payment.reserved","eventDate":"` + eventDate + `","data":{"type":"payment","id":"1"}}`
+
+		notification, err := ParseWebhookNotification([]byte(body), signedHeader(validUrl, validSecret, body), validUrl, validSecret)
+		if assert.Nil(t, err, eventDate) {
+			assert.Equal(t, time.Date(2022, 2, 20, 16, 35, 0, 0, time.UTC), notification.EventDate.Truncate(time.Minute), eventDate)
+		}
+	}
+}
+
+func TestParseWebhookNotification_Invalid_Signature(t *testing.T) {
+	notification, err := ParseWebhookNotification([]byte(invalidBody), newHeader(true), validUrl, validSecret)
+
+	assert.ErrorIs(t, err, ErrInvalidWebhookSignature)
+	assert.Nil(t, notification)
+
+	expected := signedHeader(validUrl, validSecret, invalidBody).Get("x-mobilepay-signature")
+	assert.NotContains(t, err.Error(), expected)
+
+	_, err = ParseWebhookNotification([]byte(validBody), newHeader(false), validUrl, validSecret)
+	assert.ErrorIs(t, err, ErrMissingVerifierProperties)
+}
+
+func TestParseWebhookNotification_Invalid_Payload(t *testing.T) {
+	bodies := map[string]string{
+		"malformed json":      `{"notificationId":`,
+		"missing id":          `{"eventType":"payment.reserved","eventDate":"2022-02-20T16:35:28Z","data":{"type":"payment","id":"1"}}`,
+		"unknown event":       `{"notificationId":"1","eventType":"payment.refunded","eventDate":"2022-02-20T16:35:28Z","data":{"type":"payment","id":"1"}}`,
+		"missing event date":  `{"notificationId":"1","eventType":"payment.reserved","data":{"type":"payment","id":"1"}}`,
+		"malformed eventDate": `{"notificationId":"1","eventType":"payment.reserved","eventDate":"20/02/2022","data":{"type":"payment","id":"1"}}`,
+		"missing data":        `{"notificationId":"1","eventType":"payment.reserved","eventDate":"2022-02-20T16:35:28Z"}`,
+	}
+
+	for name, body := range bodies {
+		notification, err := ParseWebhookNotification([]byte(body), signedHeader(validUrl, validSecret, body), validUrl, validSecret)
+
+		assert.ErrorIs(t, err, ErrInvalidWebhookNotification, name)
+		assert.Nil(t, notification, name)
+	}
+}
+
+func TestWebhookEvent_Enum(t *testing.T) {
+	assert.Equal(t, PaymentReserved, WebhookEvent("payment.reserved").Enum())
+	assert.Equal(t, PaymentPointActivated, PaymentPointActivated.Name().Enum())
+	assert.Equal(t, Unknown, WebhookEvent("Unknown").Enum())
+	assert.Equal(t, Unknown, WebhookEvent("payment.refunded").Enum())
+}
@@ -144,3 +144,22 @@ func TestWebhooks_Delete(t *testing.T) {
 
 	assert.Nil(t, err)
 }
+
+func TestWebhooks_PublishTestNotification(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Post("/v1/webhooks/e4a2e195-74f6-42e1-a172-83291c9d2a41/publishtestnotification").
+		Reply(204)
+
+	client := New("test", "test", config)
+	ctx := context.TODO()
+
+	err := client.Webhook.PublishTestNotification(ctx, "e4a2e195-74f6-42e1-a172-83291c9d2a41")
+
+	assert.Nil(t, err)
+	assert.True(t, gock.IsDone())
+
+	err = client.Webhook.PublishTestNotification(ctx, "")
+	assert.IsType(t, &ArgError{}, err)
+}
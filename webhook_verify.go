@@ -0,0 +1,181 @@
+package mobilepay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultWebhookVerifyTimeout is how long Verify waits for the test notification
+// when the context has no deadline.
+const DefaultWebhookVerifyTimeout = 30 * time.Second
+
+// WebhookVerifyOption configures WebhookServiceOp.Verify.
+type WebhookVerifyOption func(*webhookVerifyOptions)
+
+type webhookVerifyOptions struct {
+	listener net.Listener
+	addr     string
+}
+
+// WithVerifyListener makes Verify serve the webhook on l. Wrap l with tls.NewListener to
+// serve HTTPS. Verify takes ownership of l and closes it when it returns, also when it fails.
+func WithVerifyListener(l net.Listener) WebhookVerifyOption {
+	return func(o *webhookVerifyOptions) {
+		o.listener = l
+	}
+}
+
+// WithVerifyAddr makes Verify listen for plain HTTP on the TCP address addr, e.g. ":8080",
+// which suits endpoints behind a proxy terminating TLS.
+func WithVerifyAddr(addr string) WebhookVerifyOption {
+	return func(o *webhookVerifyOptions) {
+		o.addr = addr
+	}
+}
+
+// errVerifyingWebhook rejects the notifications other than the test notification that
+// MobilePay delivers while Verify serves the webhook, so they are delivered again.
+var errVerifyingWebhook = errors.New("the webhook is being verified, the notification will be delivered again")
+
+// Verify proves that MobilePay can reach the url of the webhook and that its notifications are
+// accepted with the signature key of the webhook. It serves a WebhookHandler for the webhook on a
+// local listener, publishes a test notification and waits until the notification has been received.
+//
+// Verify serves the webhook on the listener given with WithVerifyListener or the address given
+// with WithVerifyAddr, one of which is required. Other notifications received meanwhile are
+// answered with 500, so MobilePay delivers them again. A test notification rejected by the
+// handler makes Verify fail with ErrInvalidWebhookSignature or ErrInvalidWebhookNotification,
+// no notification at all with ErrTestNotificationNotReceived.
+func (s *WebhookServiceOp) Verify(ctx context.Context, webhook *Webhook, opts ...WebhookVerifyOption) error {
+	options := &webhookVerifyOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	err := validateVerifiedWebhook(webhook)
+	if err == nil && options.listener == nil && options.addr == "" {
+		err = newArgError("listener", "set one with WithVerifyListener or WithVerifyAddr")
+	}
+
+	if err != nil {
+		s.client.Logger.Errorf("invalid webhook: %v", err)
+
+		if options.listener != nil {
+			options.listener.Close()
+		}
+
+		return err
+	}
+
+	listener := options.listener
+	if listener == nil {
+		listener, err = net.Listen("tcp", options.addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultWebhookVerifyTimeout)
+		defer cancel()
+	}
+
+	received := make(chan struct{}, 1)
+	rejected := make(chan int, 1)
+
+	handler := NewWebhookHandler(webhook.Url, webhook.SignatureKey)
+	handler.Logger = s.client.Logger
+	handler.OnTestNotification(func(ctx context.Context, notification *WebhookNotification) error {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+
+		return nil
+	})
+	handler.fallback = func(ctx context.Context, notification *WebhookNotification) error {
+		return errVerifyingWebhook
+	}
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, r)
+
+		// only POST requests can be notifications, anything else is e.g. a health check. Server
+		// errors are the other notifications being rejected until they are delivered again.
+		if r.Method == http.MethodPost && recorder.status >= http.StatusBadRequest && recorder.status < http.StatusInternalServerError {
+			select {
+			case rejected <- recorder.status:
+			default:
+			}
+		}
+	})}
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer shutdown(server)
+
+	if err := s.PublishTestNotification(ctx, webhook.WebhookId); err != nil {
+		return err
+	}
+
+	select {
+	case <-received:
+		return nil
+	case status := <-rejected:
+		if status == http.StatusUnauthorized {
+			return fmt.Errorf("%w: the test notification was rejected", ErrInvalidWebhookSignature)
+		}
+
+		return fmt.Errorf("%w: the test notification was rejected with status %d", ErrInvalidWebhookNotification, status)
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", ErrTestNotificationNotReceived, ctx.Err())
+	}
+}
+
+func validateVerifiedWebhook(webhook *Webhook) error {
+	if webhook == nil {
+		return newArgError("webhook", "cannot be nil")
+	}
+
+	if err := validateId("webhookId", webhook.WebhookId); err != nil {
+		return err
+	}
+
+	switch {
+	case webhook.Url == "":
+		return newArgError("url", "cannot be empty")
+	case webhook.SignatureKey == "":
+		return newArgError("signatureKey", "cannot be empty")
+	}
+
+	return nil
+}
+
+// shutdown stops server once the response to the test notification has been sent, so
+// MobilePay does not consider the delivery failed.
+func shutdown(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		server.Close()
+	}
+}
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
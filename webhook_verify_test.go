@@ -0,0 +1,146 @@
+package mobilepay
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// publishTransport answers the publishtestnotification request like MobilePay and then
+// delivers body to the webhook, signed with signatureKey.
+type publishTransport struct {
+	webhookUrl   string
+	signatureKey string
+	body         string
+}
+
+func (t *publishTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/publishtestnotification") {
+		return nil, errors.New("unexpected request")
+	}
+
+	if t.body != "" {
+		go func() {
+			notification, _ := http.NewRequest(http.MethodPost, t.webhookUrl, strings.NewReader(t.body))
+			notification.Header = signedHeader(t.webhookUrl, t.signatureKey, t.body)
+
+			resp, err := (&http.Client{Transport: &http.Transport{}}).Do(notification)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusNoContent,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+func newVerifyWebhook(t *testing.T) (*Webhook, net.Listener) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Webhook{
+		WebhookId:    "e4a2e195-74f6-42e1-a172-83291c9d2a41",
+		SignatureKey: validSecret,
+		Url:          "http://" + listener.Addr().String() + "/webhooks",
+	}, listener
+}
+
+func newVerifyClient(t *testing.T, transport *publishTransport) *Client {
+	client, err := NewWithOptions("test", "test",
+		WithBaseURL(TestBaseUrl),
+		WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestWebhooks_Verify(t *testing.T) {
+	webhook, listener := newVerifyWebhook(t)
+	client := newVerifyClient(t, &publishTransport{webhookUrl: webhook.Url, signatureKey: webhook.SignatureKey, body: validBody})
+
+	err := client.Webhook.Verify(context.TODO(), webhook, WithVerifyListener(listener))
+	assert.Nil(t, err)
+}
+
+func TestWebhooks_Verify_Invalid_Signature(t *testing.T) {
+	webhook, listener := newVerifyWebhook(t)
+	client := newVerifyClient(t, &publishTransport{webhookUrl: webhook.Url, signatureKey: "wrong key", body: validBody})
+
+	err := client.Webhook.Verify(context.TODO(), webhook, WithVerifyListener(listener))
+	assert.ErrorIs(t, err, ErrInvalidWebhookSignature)
+}
+
+func TestWebhooks_Verify_Not_Received(t *testing.T) {
+	webhook, listener := newVerifyWebhook(t)
+	client := newVerifyClient(t, &publishTransport{webhookUrl: webhook.Url})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := client.Webhook.Verify(ctx, webhook, WithVerifyListener(listener))
+	assert.ErrorIs(t, err, ErrTestNotificationNotReceived)
+}
+
+func TestWebhooks_Verify_Invalid_Webhook(t *testing.T) {
+	webhook, listener := newVerifyWebhook(t)
+	webhook.SignatureKey = ""
+	client := newVerifyClient(t, &publishTransport{})
+
+	err := client.Webhook.Verify(context.TODO(), webhook, WithVerifyListener(listener))
+	assert.IsType(t, &ArgError{}, err)
+
+	_, err = listener.Accept()
+	assert.Error(t, err, "the listener should be closed")
+
+	err = client.Webhook.Verify(context.TODO(), nil)
+	assert.IsType(t, &ArgError{}, err)
+}
+
+func TestWebhooks_Verify_Requires_Listener(t *testing.T) {
+	webhook, listener := newVerifyWebhook(t)
+	listener.Close()
+	client := newVerifyClient(t, &publishTransport{})
+
+	err := client.Webhook.Verify(context.TODO(), webhook)
+	assert.IsType(t, &ArgError{}, err)
+}
+
+func TestWebhooks_Verify_Rejects_Other_Notifications(t *testing.T) {
+	webhook, listener := newVerifyWebhook(t)
+	client := newVerifyClient(t, &publishTransport{webhookUrl: webhook.Url, signatureKey: webhook.SignatureKey, body: validBody})
+
+	statuses := make(chan int, 1)
+	client.Logger = &LeveledLogger{Level: LevelNull}
+	client.onRequestCompleted = func(*http.Request, *http.Response) {
+		body := strings.Replace(validBody, string(TestNotification.Name()), string(PaymentReserved.Name()), 1)
+		notification, _ := http.NewRequest(http.MethodPost, webhook.Url, strings.NewReader(body))
+		notification.Header = signedHeader(webhook.Url, webhook.SignatureKey, body)
+
+		resp, err := (&http.Client{Transport: &http.Transport{}}).Do(notification)
+		if err == nil {
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}
+	}
+
+	err := client.Webhook.Verify(context.TODO(), webhook, WithVerifyListener(listener))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, <-statuses)
+}
@@ -0,0 +1,171 @@
+package mobilepay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultWireLogMaxBodyBytes is the number of body bytes included in a wire dump when
+// WithWireLogging is given zero.
+const DefaultWireLogMaxBodyBytes = 4 << 10
+
+// maxRedactedBodyBytes is the size up to which a response body is buffered for a dump. Larger
+// bodies are left out, as sensitive fields can only be masked in a complete JSON document.
+const maxRedactedBodyBytes = 1 << 20
+
+// wireLogger dumps the requests sent to and the responses received from MobilePay.
+type wireLogger struct {
+	// maxBodyBytes is the number of body bytes included in a dump.
+	maxBodyBytes int
+}
+
+// wireLoggingEnabled reports whether wire dumps are enabled and would be emitted by the logger.
+func (c *Client) wireLoggingEnabled() bool {
+	if c.wireLogger == nil || c.Logger == nil {
+		return false
+	}
+
+	if logger, ok := c.Logger.(*LeveledLogger); ok {
+		return logger.Level >= LevelDebug
+	}
+
+	return true
+}
+
+// dumpRequest logs the redacted headers and body of req at debug level. The body is read
+// through GetBody, so req.Body is left untouched for the transport and for retries.
+func (c *Client) dumpRequest(req *http.Request, attempt int) {
+	if !c.wireLoggingEnabled() {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\n", req.Method, c.redactor.URL(req.URL))
+	fmt.Fprintf(&b, "Host: %s\n", req.URL.Host)
+	c.writeHeader(&b, req.Header)
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			b.WriteString("\n[body not replayable]")
+		} else if body, err := req.GetBody(); err != nil {
+			fmt.Fprintf(&b, "\n[body unavailable: %v]", err)
+		} else {
+			data, err := ioutil.ReadAll(body)
+			_ = body.Close()
+			if err != nil {
+				fmt.Fprintf(&b, "\n[body unavailable: %v]", err)
+			} else {
+				c.writeBody(&b, data)
+			}
+		}
+	}
+
+	c.Logger.Debugf("MobilePay request dump (attempt %d):\n%s", attempt, b.String())
+}
+
+// dumpResponse logs the redacted headers and body of resp at debug level. At most
+// maxRedactedBodyBytes of the body are buffered and put back in front of the rest of it,
+// so it can still be decoded afterwards.
+func (c *Client) dumpResponse(resp *http.Response) {
+	if !c.wireLoggingEnabled() || resp == nil {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", resp.Proto, resp.Status)
+	c.writeHeader(&b, resp.Header)
+
+	if resp.Body != nil && resp.Body != http.NoBody {
+		limit := int64(maxRedactedBodyBytes)
+		if max := int64(c.wireLogger.maxBodyBytes); max > limit {
+			limit = max
+		}
+
+		data, err := peekBody(resp, limit+1)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&b, "\n[body unavailable: %v]", err)
+		case int64(len(data)) > limit:
+			fmt.Fprintf(&b, "\n[body larger than %d bytes omitted]", limit)
+		default:
+			c.writeBody(&b, data)
+		}
+	}
+
+	c.Logger.Debugf("MobilePay response dump:\n%s", b.String())
+}
+
+func (c *Client) writeHeader(b *strings.Builder, header http.Header) {
+	redacted := c.redactor.Header(header)
+
+	keys := make([]string, 0, len(redacted))
+	for k := range redacted {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s: %s\n", k, strings.Join(redacted[k], ", "))
+	}
+}
+
+// writeBody writes the redacted body, truncated to the configured size.
+func (c *Client) writeBody(b *strings.Builder, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	body := c.redactor.JSON(data)
+	b.WriteString("\n")
+	if max := c.wireLogger.maxBodyBytes; len(body) > max {
+		b.Write(body[:max])
+		fmt.Fprintf(b, "... [truncated %d bytes]", len(body)-max)
+		return
+	}
+	b.Write(body)
+}
+
+// peekBody reads at most n bytes of the body of resp and puts them back in front of the rest
+// of the body, so the body can still be read in full afterwards.
+func peekBody(resp *http.Response, n int64) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, n))
+
+	var rest io.Reader = resp.Body
+	if err != nil {
+		// preserve the read error for whoever reads the body next.
+		rest = &errorAfterReader{err: err}
+	}
+
+	resp.Body = &peekedBody{Reader: io.MultiReader(bytes.NewReader(data), rest), Closer: resp.Body}
+
+	return data, err
+}
+
+// peekedBody is a response body whose beginning has been read by peekBody.
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// errorAfterReader returns data and then err, preserving a read error that occurred while
+// buffering a response body for a dump.
+type errorAfterReader struct {
+	data []byte
+	err  error
+}
+
+func (r *errorAfterReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
@@ -0,0 +1,160 @@
+package mobilepay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func newWireClient(t *testing.T, level Level, maxBodyBytes int) (*Client, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+	logger := &LeveledLogger{Level: level, stdoutOverride: &stdout, stderrOverride: &stderr}
+
+	c, err := NewWithOptions(secretClientId, secretApiKey,
+		WithBaseURL(TestBaseUrl),
+		WithLogger(logger),
+		WithWireLogging(maxBodyBytes),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+	)
+	assert.NoError(t, err)
+
+	return c, &stdout
+}
+
+func TestWireLogging_Dumps_Redacted_Request_And_Response(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks/e4a2e195-74f6-42e1-a172-83291c9d2a41").
+		Reply(200).
+		SetHeader("CorrelationId", "4a4e1b2c").
+		JSON(map[string]string{
+			"webhookId":    "e4a2e195-74f6-42e1-a172-83291c9d2a41",
+			"signatureKey": secretSigKey,
+		})
+
+	c, stdout := newWireClient(t, LevelDebug, 0)
+
+	webhook, err := c.Webhook.Find(context.TODO(), "e4a2e195-74f6-42e1-a172-83291c9d2a41")
+	assert.Nil(t, err)
+	assert.Equal(t, "e4a2e195-74f6-42e1-a172-83291c9d2a41", webhook.WebhookId)
+	assert.Equal(t, secretSigKey, webhook.SignatureKey, "the dump must not consume the response body")
+
+	output := stdout.String()
+	assert.Contains(t, output, "MobilePay request dump (attempt 1):\nGET "+TestBaseUrl+"/v1/webhooks/e4a2e195-74f6-42e1-a172-83291c9d2a41 HTTP/1.1")
+	assert.Contains(t, output, "Authorization: [REDACTED]")
+	assert.Contains(t, output, "X-Ibm-Client-Id: [REDACTED]")
+	assert.Contains(t, output, "MobilePay response dump:\nHTTP/1.1 200 OK")
+	assert.Contains(t, output, "Correlationid: 4a4e1b2c")
+	assert.Contains(t, output, `"signatureKey":"[REDACTED]"`)
+	for _, secret := range []string{secretClientId, secretApiKey, secretSigKey} {
+		assert.NotContains(t, output, secret)
+	}
+	assert.True(t, gock.IsDone())
+}
+
+func TestWireLogging_Does_Not_Consume_Request_Body_Before_Retry(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	params := &RefundParams{
+		Amount:         100,
+		IdempotencyKey: "7576910d-9789-4fef-a72e-877d89afec94",
+		PaymentId:      "211444eb-1c4e-4194-a58f-905d97877cc5",
+		Reference:      "test",
+		Description:    "this is a test payment",
+	}
+
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		JSON(params).
+		Reply(502)
+
+	gock.New(TestBaseUrl).
+		Post("/v1/refunds").
+		JSON(params).
+		Reply(200).
+		JSON(map[string]interface{}{"paymentId": params.PaymentId, "amount": 100})
+
+	c, stdout := newWireClient(t, LevelDebug, 0)
+
+	refund, err := c.Payment.Refund.Create(context.TODO(), params)
+	assert.Nil(t, err)
+	assert.Equal(t, 100, refund.Amount)
+	assert.True(t, gock.IsDone())
+
+	output := stdout.String()
+	assert.Contains(t, output, "MobilePay request dump (attempt 2):")
+	assert.Equal(t, 2, strings.Count(output, `"idempotencyKey":"7576910d-9789-4fef-a72e-877d89afec94"`))
+}
+
+func TestWireLogging_Truncates_Bodies(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	c, stdout := newWireClient(t, LevelDebug, 10)
+
+	_, err := c.Webhook.Get(context.TODO())
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "{\"webhooks... [truncated 6 bytes]")
+}
+
+func TestWireLogging_Omits_Large_Bodies(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	padding := strings.Repeat("a", maxRedactedBodyBytes)
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		BodyString(`{"webhooks":[{"webhookId":"1","signatureKey":"` + padding + `"}]}`)
+
+	c, stdout := newWireClient(t, LevelDebug, 0)
+
+	root, err := c.Webhook.Get(context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, padding, root.Webhooks[0].SignatureKey)
+	assert.Contains(t, stdout.String(), fmt.Sprintf("[body larger than %d bytes omitted]", maxRedactedBodyBytes))
+	assert.NotContains(t, stdout.String(), padding[:100])
+}
+
+func TestPeekBody(t *testing.T) {
+	body := strings.Repeat("a", maxCorrelationIDBodyBytes) + `{"correlationId":"1"}`
+	resp := &http.Response{StatusCode: 500, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(body))}
+
+	assert.Equal(t, "", responseCorrelationID(resp))
+
+	data, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, body, string(data))
+}
+
+func TestWireLogging_Requires_Debug_Level(t *testing.T) {
+	defer gock.Off() // Flush pending mocks after test execution
+
+	gock.New(TestBaseUrl).
+		Get("/v1/webhooks").
+		Reply(200).
+		JSON(map[string]interface{}{"webhooks": []interface{}{}})
+
+	c, stdout := newWireClient(t, LevelInfo, 0)
+
+	_, err := c.Webhook.Get(context.TODO())
+	assert.Nil(t, err)
+	assert.NotContains(t, stdout.String(), "dump")
+}
+
+func TestWithWireLogging_Negative(t *testing.T) {
+	_, err := NewWithOptions("id", "key", WithWireLogging(-1))
+	assert.Error(t, err)
+}